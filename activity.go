@@ -0,0 +1,127 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// ActivityWeek contains the activity of a week.
+type ActivityWeek struct {
+	// The start of the week, i.e. Sunday 00:00:00 in UTC.
+	Week time.Time
+	// The number of commits made in the week.
+	Commits int
+	// The number of added lines in the week.
+	Additions int
+	// The number of deleted lines in the week.
+	Deletions int
+}
+
+// AuthorActivity contains the activity of an author.
+type AuthorActivity struct {
+	// The name of the author, as mapped by the ".mailmap".
+	Name string
+	// The email of the author, as mapped by the ".mailmap".
+	Email string
+	// The number of commits made by the author.
+	Commits int
+	// The number of added lines by the author.
+	Additions int
+	// The number of deleted lines by the author.
+	Deletions int
+	// The weekly activity of the author in chronological order. Weeks without
+	// any commits are not included.
+	Weeks []*ActivityWeek
+
+	weeks map[int64]*ActivityWeek
+}
+
+// ActivityStats contains the contributor and activity statistics of a range of
+// commits.
+type ActivityStats struct {
+	// The total number of commits.
+	TotalCommits int
+	// The activity of each author, sorted by the number of commits in descending
+	// order.
+	Authors []*AuthorActivity
+	// The weekly activity of all authors (i.e. code frequency) in chronological
+	// order. Weeks without any commits are not included.
+	Weeks []*ActivityWeek
+	// The number of commits by weekday (Sunday=0) and hour of the day in the
+	// author's local time zone.
+	PunchCard [7][24]int
+
+	authors map[string]*AuthorActivity
+	weeks   map[int64]*ActivityWeek
+}
+
+// activityWeekStart returns the start of the week of given time in UTC.
+func activityWeekStart(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
+
+// addCommit records a commit of given author at given time with its line
+// statistics.
+func (s *ActivityStats) addCommit(name, email string, when time.Time, additions, deletions int) {
+	if s.authors == nil {
+		s.authors = make(map[string]*AuthorActivity)
+		s.weeks = make(map[int64]*ActivityWeek)
+	}
+
+	key := strings.ToLower(email)
+	author, ok := s.authors[key]
+	if !ok {
+		author = &AuthorActivity{
+			Name:  name,
+			Email: email,
+			weeks: make(map[int64]*ActivityWeek),
+		}
+		s.authors[key] = author
+		s.Authors = append(s.Authors, author)
+	}
+
+	week := activityWeekStart(when)
+	addWeek := func(weeks map[int64]*ActivityWeek, list *[]*ActivityWeek) {
+		w, ok := weeks[week.Unix()]
+		if !ok {
+			w = &ActivityWeek{Week: week}
+			weeks[week.Unix()] = w
+			*list = append(*list, w)
+		}
+		w.Commits++
+		w.Additions += additions
+		w.Deletions += deletions
+	}
+	addWeek(author.weeks, &author.Weeks)
+	addWeek(s.weeks, &s.Weeks)
+
+	s.TotalCommits++
+	author.Commits++
+	author.Additions += additions
+	author.Deletions += deletions
+	s.PunchCard[when.Weekday()][when.Hour()]++
+}
+
+// sort sorts authors and weeks after all commits have been added.
+func (s *ActivityStats) sort() {
+	byWeek := func(weeks []*ActivityWeek) {
+		sort.Slice(weeks, func(i, j int) bool {
+			return weeks[i].Week.Before(weeks[j].Week)
+		})
+	}
+
+	sort.SliceStable(s.Authors, func(i, j int) bool {
+		return s.Authors[i].Commits > s.Authors[j].Commits
+	})
+	for _, author := range s.Authors {
+		byWeek(author.Weeks)
+	}
+	byWeek(s.Weeks)
+}
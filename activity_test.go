@@ -0,0 +1,79 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_activityWeekStart(t *testing.T) {
+	tests := []struct {
+		when time.Time
+		want time.Time
+	}{
+		{
+			when: time.Date(2020, 2, 9, 20, 18, 0, 0, time.UTC), // Sunday
+			want: time.Date(2020, 2, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			when: time.Date(2020, 2, 15, 23, 59, 59, 0, time.UTC), // Saturday
+			want: time.Date(2020, 2, 9, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Monday in local time but still Sunday in UTC
+			when: time.Date(2020, 2, 10, 6, 0, 0, 0, time.FixedZone("", 8*60*60)),
+			want: time.Date(2020, 2, 9, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, test.want, activityWeekStart(test.when))
+		})
+	}
+}
+
+func TestActivityStats_addCommit(t *testing.T) {
+	var stats ActivityStats
+	stats.addCommit("Alice", "alice@example.com", time.Date(2020, 2, 3, 10, 0, 0, 0, time.UTC), 1, 2)
+	stats.addCommit("Bob", "bob@example.com", time.Date(2020, 2, 10, 11, 0, 0, 0, time.UTC), 3, 4)
+	stats.addCommit("Bob", "BOB@example.com", time.Date(2020, 2, 4, 11, 0, 0, 0, time.UTC), 5, 6)
+	stats.sort()
+
+	assert.Equal(t, 3, stats.TotalCommits)
+	if assert.Len(t, stats.Authors, 2) {
+		bob := stats.Authors[0]
+		assert.Equal(t, "bob@example.com", bob.Email)
+		assert.Equal(t, 2, bob.Commits)
+		assert.Equal(t, 8, bob.Additions)
+		assert.Equal(t, 10, bob.Deletions)
+		if assert.Len(t, bob.Weeks, 2) {
+			assert.Equal(t, time.Date(2020, 2, 2, 0, 0, 0, 0, time.UTC), bob.Weeks[0].Week)
+			assert.Equal(t, time.Date(2020, 2, 9, 0, 0, 0, 0, time.UTC), bob.Weeks[1].Week)
+		}
+		assert.Equal(t, "alice@example.com", stats.Authors[1].Email)
+	}
+
+	want := []*ActivityWeek{
+		{
+			Week:      time.Date(2020, 2, 2, 0, 0, 0, 0, time.UTC),
+			Commits:   2,
+			Additions: 6,
+			Deletions: 8,
+		},
+		{
+			Week:      time.Date(2020, 2, 9, 0, 0, 0, 0, time.UTC),
+			Commits:   1,
+			Additions: 3,
+			Deletions: 4,
+		},
+	}
+	assert.Equal(t, want, stats.Weeks)
+	assert.Equal(t, 1, stats.PunchCard[time.Monday][10])
+	assert.Equal(t, 1, stats.PunchCard[time.Monday][11])
+	assert.Equal(t, 1, stats.PunchCard[time.Tuesday][11])
+}
@@ -4,16 +4,304 @@
 
 package git
 
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// BlameHunk is a range of consecutive lines of the blamed file that come from
+// the same commit.
+type BlameHunk struct {
+	// The commit that introduced the lines.
+	Commit *Commit
+	// The path of the file in the commit, which differs from the blamed path when
+	// the file was renamed or the lines were moved or copied from another file.
+	Path string
+	// The 1-based line number of the first line in the file of the commit.
+	OrigLine int
+	// The 1-based line number of the first line in the blamed file.
+	Line int
+	// The number of lines.
+	NumLines int
+	// Indicates whether the commit is a boundary of the blame, e.g. the root
+	// commit. The lines may come from older commits that are not looked into.
+	Boundary bool
+	// Indicates whether the lines are attributed through ignored revisions, i.e.
+	// the commit is the best guess of where the lines came from before the
+	// ignored revisions changed them.
+	Ignored bool
+	// Indicates whether the lines were changed by an ignored revision but could
+	// not be attributed to any other commit, thus the commit is the ignored
+	// revision itself.
+	Unblamable bool
+	// The parent of the commit that the lines are blamed against, if any. It is
+	// not reported when any revision is ignored.
+	PreviousID *SHA1
+	// The path of the file in the parent of the commit.
+	PreviousPath string
+}
+
+// EndLine returns the 1-based line number of the last line of the hunk in the
+// blamed file.
+func (h *BlameHunk) EndLine() int {
+	return h.Line + h.NumLines - 1
+}
+
 // Blame contains information of a Git file blame.
 type Blame struct {
-	lines []*Commit
+	// Hunks are sorted by their line numbers in the blamed file.
+	hunks []*BlameHunk
+}
+
+// Hunks returns hunks of the blame in the order of lines of the blamed file.
+func (b *Blame) Hunks() []*BlameHunk {
+	return b.hunks
+}
+
+// Hunk returns the hunk containing the line by given line number (1-based). It
+// returns nil when no such line.
+func (b *Blame) Hunk(i int) *BlameHunk {
+	n := sort.Search(len(b.hunks), func(j int) bool {
+		return b.hunks[j].EndLine() >= i
+	})
+	if n == len(b.hunks) || b.hunks[n].Line > i {
+		return nil
+	}
+	return b.hunks[n]
 }
 
 // Line returns the commit by given line number (1-based). It returns nil when
 // no such line.
 func (b *Blame) Line(i int) *Commit {
-	if i <= 0 || len(b.lines) < i {
+	hunk := b.Hunk(i)
+	if hunk == nil {
 		return nil
 	}
-	return b.lines[i-1]
+	return hunk.Commit
+}
+
+// parseBlame parses the output of "git blame --porcelain" or "git blame
+// --incremental", and calls fn with each hunk as soon as it is complete. The
+// commit of each hunk is resolved by the given function once per commit, e.g.
+//
+//	b26c8f1596a61ca32d4797b6735e2b80f63f06fa 2 2 1
+//	author alice
+//	...
+//	summary Update README
+//	previous b767667d9e0f279e56391971d66bf68146cbf60e README.txt
+//	filename README.txt
+//		the content of the line (porcelain only)
+//	b767667d9e0f279e56391971d66bf68146cbf60e 3 3 2
+//	boundary
+//	filename README.txt
+//
+// The porcelain format omits the "previous" and "filename" headers when the
+// commit has been shown, in which case the hunk ends at its first line of
+// content and takes the ones of the last hunk of the same commit.
+func parseBlame(r io.Reader, commit func(id string) (*Commit, error), fn func(*BlameHunk) error) error {
+	commits := make(map[string]*Commit)
+	boundaries := make(map[string]bool)
+	lasts := make(map[string]*BlameHunk)
+
+	var current *BlameHunk
+	var currentID string
+	var hasPrevious bool
+	emit := func() error {
+		c, ok := commits[currentID]
+		if !ok {
+			var err error
+			c, err = commit(currentID)
+			if err != nil {
+				return err
+			}
+			commits[currentID] = c
+		}
+		current.Commit = c
+		current.Boundary = boundaries[currentID]
+		lasts[currentID] = current
+
+		hunk := current
+		current = nil
+		return fn(hunk)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		// Lines of the content in porcelain format
+		if len(line) > 0 && line[0] == '\t' {
+			if current != nil {
+				if err := emit(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		key, value := line, []byte(nil)
+		if i := bytes.IndexByte(line, ' '); i > -1 {
+			key, value = line[:i], line[i+1:]
+		}
+
+		switch {
+		case len(key) == 40 && len(value) > 0 && value[0] >= '0' && value[0] <= '9':
+			fields := bytes.Fields(value)
+			// Subsequent lines of the same hunk in porcelain format only have line
+			// numbers.
+			if len(fields) != 3 {
+				continue
+			}
+
+			origLine, err := strconv.Atoi(string(fields[0]))
+			if err != nil {
+				return fmt.Errorf("parse original line number: %v", err)
+			}
+			finalLine, err := strconv.Atoi(string(fields[1]))
+			if err != nil {
+				return fmt.Errorf("parse final line number: %v", err)
+			}
+			numLines, err := strconv.Atoi(string(fields[2]))
+			if err != nil {
+				return fmt.Errorf("parse number of lines: %v", err)
+			}
+
+			currentID = string(key)
+			current = &BlameHunk{
+				OrigLine: origLine,
+				Line:     finalLine,
+				NumLines: numLines,
+			}
+			hasPrevious = false
+			if last := lasts[currentID]; last != nil {
+				current.Path = last.Path
+				current.PreviousID = last.PreviousID
+				current.PreviousPath = last.PreviousPath
+			}
+
+		case current == nil:
+			return fmt.Errorf("unexpected line outside of blame entry: %q", line)
+
+		case bytes.Equal(key, []byte("boundary")):
+			boundaries[currentID] = true
+
+		case bytes.Equal(key, []byte("previous")):
+			fields := bytes.SplitN(value, []byte(" "), 2)
+			if len(fields) != 2 {
+				return fmt.Errorf("malformed previous: %q", line)
+			}
+			id, err := NewIDFromString(string(fields[0]))
+			if err != nil {
+				return fmt.Errorf("parse previous: %v", err)
+			}
+			current.PreviousID = id
+			current.PreviousPath = unquotePath(string(fields[1]))
+			hasPrevious = true
+
+		case bytes.Equal(key, []byte("filename")):
+			// The filename is the last header of each entry, along with the previous
+			// if any.
+			current.Path = unquotePath(string(value))
+			if !hasPrevious {
+				current.PreviousID = nil
+				current.PreviousPath = ""
+			}
+			if err := emit(); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// blameLinePattern matches the beginning of each line in the output of "git
+// blame -l -s -n -f" until the content, e.g.
+//
+//	?b26c8f1596a61ca32d4797b6735e2b80f63f06 README.txt 5 5) the content
+//
+// The path is padded with spaces to the length of the longest path, thus its
+// trailing spaces are indistinguishable from the padding.
+var blameLinePattern = regexp.MustCompile(`^([\^*?]*)([0-9a-f]+) (.*?) +([0-9]+) +([0-9]+)\) `)
+
+// parseBlameLines parses the output of "git blame -l -s -n -f", in which lines
+// of boundary commits are prefixed with "^", and lines that are attributed
+// through ignored revisions or unblamable are prefixed with "?" or "*"
+// respectively. It calls fn with each hunk of consecutive lines from the same
+// commit. The commit ID of a line is shortened by the length of its markers,
+// and it is resolved by the given function once per distinct ID.
+func parseBlameLines(r io.Reader, commit func(id string) (*Commit, error), fn func(*BlameHunk) error) error {
+	commits := make(map[string]*Commit)
+	var current *BlameHunk
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		m := blameLinePattern.FindSubmatch(line)
+		if m == nil {
+			return fmt.Errorf("malformed blame line: %q", line)
+		}
+
+		id := string(m[2])
+		c, ok := commits[id]
+		if !ok {
+			var err error
+			c, err = commit(id)
+			if err != nil {
+				return err
+			}
+			commits[id] = c
+		}
+
+		origLine, err := strconv.Atoi(string(m[4]))
+		if err != nil {
+			return fmt.Errorf("parse original line number: %v", err)
+		}
+		finalLine, err := strconv.Atoi(string(m[5]))
+		if err != nil {
+			return fmt.Errorf("parse final line number: %v", err)
+		}
+
+		hunk := &BlameHunk{
+			Commit:     c,
+			Path:       string(m[3]),
+			OrigLine:   origLine,
+			Line:       finalLine,
+			NumLines:   1,
+			Boundary:   bytes.IndexByte(m[1], '^') > -1,
+			Ignored:    bytes.IndexByte(m[1], '?') > -1,
+			Unblamable: bytes.IndexByte(m[1], '*') > -1,
+		}
+		if current != nil &&
+			current.Commit.ID.Equal(hunk.Commit.ID) &&
+			current.Path == hunk.Path &&
+			current.Boundary == hunk.Boundary &&
+			current.Ignored == hunk.Ignored &&
+			current.Unblamable == hunk.Unblamable &&
+			current.OrigLine+current.NumLines == hunk.OrigLine &&
+			current.Line+current.NumLines == hunk.Line {
+			current.NumLines++
+			continue
+		}
+
+		if current != nil {
+			if err = fn(current); err != nil {
+				return err
+			}
+		}
+		current = hunk
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if current != nil {
+		return fn(current)
+	}
+	return nil
 }
@@ -0,0 +1,202 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseBlame(t *testing.T) {
+	output := `b26c8f1596a61ca32d4797b6735e2b80f63f06fa 2 2 1
+author alice
+author-mail <alice@example.com>
+author-time 1792330047
+author-tz +0000
+committer alice
+committer-mail <alice@example.com>
+committer-time 1792330047
+committer-tz +0000
+summary Update a
+previous b767667d9e0f279e56391971d66bf68146cbf60e "a\tb.txt"
+filename a.txt
+	two
+b767667d9e0f279e56391971d66bf68146cbf60e 3 3 2
+author alice
+author-mail <alice@example.com>
+author-time 1792330047
+author-tz +0000
+committer alice
+committer-mail <alice@example.com>
+committer-time 1792330047
+committer-tz +0000
+summary Add a
+boundary
+filename a.txt
+	3
+b767667d9e0f279e56391971d66bf68146cbf60e 4 4
+	4
+b767667d9e0f279e56391971d66bf68146cbf60e 1 5 1
+	1
+b26c8f1596a61ca32d4797b6735e2b80f63f06fa 6 6 1
+	6
+`
+	var ids []string
+	commit := func(id string) (*Commit, error) {
+		ids = append(ids, id)
+		return &Commit{ID: MustIDFromString(id)}, nil
+	}
+
+	var hunks []*BlameHunk
+	err := parseBlame(strings.NewReader(output), commit, func(hunk *BlameHunk) error {
+		hunks = append(hunks, hunk)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Each commit is only resolved once
+	assert.Equal(t, []string{"b26c8f1596a61ca32d4797b6735e2b80f63f06fa", "b767667d9e0f279e56391971d66bf68146cbf60e"}, ids)
+
+	require.Len(t, hunks, 4)
+	assert.Equal(t, "b26c8f1596a61ca32d4797b6735e2b80f63f06fa", hunks[0].Commit.ID.String())
+	assert.Equal(t, "a.txt", hunks[0].Path)
+	assert.Equal(t, 2, hunks[0].OrigLine)
+	assert.Equal(t, 2, hunks[0].Line)
+	assert.Equal(t, 1, hunks[0].NumLines)
+	assert.False(t, hunks[0].Boundary)
+	assert.Equal(t, "b767667d9e0f279e56391971d66bf68146cbf60e", hunks[0].PreviousID.String())
+	assert.Equal(t, "a\tb.txt", hunks[0].PreviousPath)
+
+	assert.Equal(t, 3, hunks[1].Line)
+	assert.Equal(t, 4, hunks[1].EndLine())
+	assert.True(t, hunks[1].Boundary)
+	assert.Nil(t, hunks[1].PreviousID)
+
+	assert.Equal(t, 1, hunks[2].OrigLine)
+	assert.Equal(t, 5, hunks[2].Line)
+	assert.True(t, hunks[2].Boundary)
+	assert.Same(t, hunks[1].Commit, hunks[2].Commit)
+
+	// Headers are omitted for commits that have been shown
+	assert.Equal(t, "a.txt", hunks[3].Path)
+	assert.Equal(t, 6, hunks[3].Line)
+	assert.Equal(t, "a\tb.txt", hunks[3].PreviousPath)
+
+	t.Run("stop by callback", func(t *testing.T) {
+		errStop := errors.New("stop")
+		calls := 0
+		err := parseBlame(strings.NewReader(output), commit, func(*BlameHunk) error {
+			calls++
+			return errStop
+		})
+		assert.Equal(t, errStop, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		err := parseBlame(strings.NewReader("filename a.txt\n"), commit, func(*BlameHunk) error { return nil })
+		assert.Error(t, err)
+	})
+}
+
+func Test_parseBlameLines(t *testing.T) {
+	output := `?f80ad43cc39271823a9215a1c1b7eb33fcc2640 a.txt     1 1) one
+?f80ad43cc39271823a9215a1c1b7eb33fcc2640 a.txt     2 2) two
+^7d0496fe35acef4bebad6cdb352f7608744f253 a.txt     3 3) three
+^?7d0496fe35acef4bebad6cdb352f7608744f25 a b.txt   1 4) 1) four
+*6fcab5990e616acf87dffe5c1dfed2e14ff8436 a.txt     5 5) 
+c0211029802ec6c40b7bbb44954fdf914b869366 a.txt     7 6) six
+c0211029802ec6c40b7bbb44954fdf914b869366 a.txt     9 7) seven
+`
+	full := map[string]string{
+		"f80ad43cc39271823a9215a1c1b7eb33fcc2640":  "f80ad43cc39271823a9215a1c1b7eb33fcc2640f",
+		"7d0496fe35acef4bebad6cdb352f7608744f253":  "7d0496fe35acef4bebad6cdb352f7608744f2532",
+		"7d0496fe35acef4bebad6cdb352f7608744f25":   "7d0496fe35acef4bebad6cdb352f7608744f2532",
+		"6fcab5990e616acf87dffe5c1dfed2e14ff8436":  "6fcab5990e616acf87dffe5c1dfed2e14ff84366",
+		"c0211029802ec6c40b7bbb44954fdf914b869366": "c0211029802ec6c40b7bbb44954fdf914b869366",
+	}
+	var ids []string
+	commit := func(id string) (*Commit, error) {
+		ids = append(ids, id)
+		return &Commit{ID: MustIDFromString(full[id])}, nil
+	}
+
+	var hunks []*BlameHunk
+	err := parseBlameLines(strings.NewReader(output), commit, func(hunk *BlameHunk) error {
+		hunks = append(hunks, hunk)
+		return nil
+	})
+	require.NoError(t, err)
+
+	// Each distinct ID is only resolved once
+	assert.Len(t, ids, 5)
+
+	require.Len(t, hunks, 6)
+	assert.Equal(t, "f80ad43cc39271823a9215a1c1b7eb33fcc2640f", hunks[0].Commit.ID.String())
+	assert.Equal(t, "a.txt", hunks[0].Path)
+	assert.Equal(t, 1, hunks[0].Line)
+	assert.Equal(t, 2, hunks[0].NumLines)
+	assert.True(t, hunks[0].Ignored)
+	assert.False(t, hunks[0].Boundary)
+
+	assert.Equal(t, 3, hunks[1].Line)
+	assert.True(t, hunks[1].Boundary)
+	assert.False(t, hunks[1].Ignored)
+
+	assert.Equal(t, "7d0496fe35acef4bebad6cdb352f7608744f2532", hunks[2].Commit.ID.String())
+	assert.Equal(t, "a b.txt", hunks[2].Path)
+	assert.Equal(t, 1, hunks[2].OrigLine)
+	assert.Equal(t, 4, hunks[2].Line)
+	assert.True(t, hunks[2].Boundary)
+	assert.True(t, hunks[2].Ignored)
+
+	assert.Equal(t, 5, hunks[3].Line)
+	assert.True(t, hunks[3].Unblamable)
+	assert.False(t, hunks[3].Ignored)
+
+	// Lines are not consecutive in the file of the commit
+	assert.Equal(t, 6, hunks[4].Line)
+	assert.Equal(t, 1, hunks[4].NumLines)
+	assert.Equal(t, 7, hunks[5].Line)
+	assert.Equal(t, 9, hunks[5].OrigLine)
+
+	t.Run("malformed", func(t *testing.T) {
+		err := parseBlameLines(strings.NewReader("a.txt\n"), commit, func(*BlameHunk) error { return nil })
+		assert.Error(t, err)
+	})
+}
+
+func TestBlame_Hunk(t *testing.T) {
+	first := &Commit{Message: "first"}
+	second := &Commit{Message: "second"}
+	blame := &Blame{
+		hunks: []*BlameHunk{
+			{Commit: first, Line: 1, NumLines: 2},
+			{Commit: second, Line: 3, NumLines: 1},
+			// Lines 4-5 are not blamed, e.g. out of ranges
+			{Commit: first, Line: 6, NumLines: 2},
+		},
+	}
+
+	tests := []struct {
+		line      int
+		expCommit *Commit
+	}{
+		{line: 0, expCommit: nil},
+		{line: 1, expCommit: first},
+		{line: 2, expCommit: first},
+		{line: 3, expCommit: second},
+		{line: 4, expCommit: nil},
+		{line: 7, expCommit: first},
+		{line: 8, expCommit: nil},
+	}
+	for _, test := range tests {
+		assert.Same(t, test.expCommit, blame.Line(test.line), "line %d", test.line)
+	}
+}
@@ -99,9 +99,10 @@ func (c *Command) AddOptions(opts ...CommandOptions) *Command {
 	return c
 }
 
-// AddCommitter appends given committer to the command.
+// AddCommitter appends given committer to the command. The time of the
+// committer is also used when it is not zero.
 func (c *Command) AddCommitter(committer *Signature) *Command {
-	c.AddEnvs("GIT_COMMITTER_NAME="+committer.Name, "GIT_COMMITTER_EMAIL="+committer.Email)
+	c.AddEnvs(signatureEnvs("COMMITTER", committer)...)
 	return c
 }
 
@@ -24,7 +24,11 @@ type Commit struct {
 	// The full commit message.
 	Message string
 
-	parents []*SHA1
+	parents      []*SHA1
+	encoding     string
+	mergeTags    []*Tag
+	signature    *ObjectSignature
+	extraHeaders []*CommitHeader
 	*Tree
 
 	submodules     Submodules
@@ -32,11 +36,67 @@ type Commit struct {
 	submodulesErr  error
 }
 
+// withMailmap returns a copy of the commit with author and committer resolved
+// to their canonical identities by given mailmap.
+func (c *Commit) withMailmap(m *Mailmap) *Commit {
+	return &Commit{
+		ID:           c.ID,
+		Author:       m.Resolve(c.Author),
+		Committer:    m.Resolve(c.Committer),
+		Message:      c.Message,
+		parents:      c.parents,
+		encoding:     c.encoding,
+		mergeTags:    c.mergeTags,
+		signature:    c.signature,
+		extraHeaders: c.extraHeaders,
+		Tree:         c.Tree,
+	}
+}
+
 // Summary returns first line of commit message.
 func (c *Commit) Summary() string {
 	return strings.Split(c.Message, "\n")[0]
 }
 
+// Signature returns the cryptographic signature of the commit from the
+// "gpgsig" header. It returns nil if the commit is not signed.
+//
+// NOTE: The "gpgsig-sha256" header, which signs the SHA-256 form of the commit
+// in repositories with both object formats, is out of scope because the signed
+// payload is not available in SHA-1 repositories. It is kept in ExtraHeaders.
+func (c *Commit) Signature() *ObjectSignature {
+	return c.signature
+}
+
+// CommitHeader is a header of the commit object that is not otherwise parsed
+// into the Commit, e.g. "gpgsig-sha256".
+type CommitHeader struct {
+	// The key of the header.
+	Key string
+	// The value of the header. Continuation lines are joined with "\n" without
+	// their leading spaces.
+	Value string
+}
+
+// Encoding returns the encoding of the commit message as specified by the
+// "encoding" header. It returns an empty string if the header is absent, which
+// means UTF-8. The message is already transcoded to UTF-8 when possible.
+func (c *Commit) Encoding() string {
+	return c.encoding
+}
+
+// MergeTags returns the tags embedded by the "mergetag" headers of the commit,
+// which are the signed tags that were merged into the commit, in order.
+func (c *Commit) MergeTags() []*Tag {
+	return c.mergeTags
+}
+
+// ExtraHeaders returns the headers of the commit object that are not otherwise
+// parsed, in the order they appear.
+func (c *Commit) ExtraHeaders() []*CommitHeader {
+	return c.extraHeaders
+}
+
 // ParentsCount returns number of parents of the commit. It returns 0 if this is
 // the root commit, otherwise returns 1, 2, etc.
 func (c *Commit) ParentsCount() int {
@@ -85,6 +145,16 @@ func (c *Commit) ShowNameStatus(opts ...ShowNameStatusOptions) (*NameStatus, err
 	return c.repo.ShowNameStatus(c.ID.String(), opts...)
 }
 
+// Stats returns the line statistics of changed files of the commit, compared to
+// its first parent.
+func (c *Commit) Stats(opts ...DiffStatOptions) (*DiffStat, error) {
+	base := EmptyTreeID
+	if c.ParentsCount() > 0 {
+		base = c.parents[0].String()
+	}
+	return c.repo.DiffStat(base, c.ID.String(), opts...)
+}
+
 // CommitsCount returns number of total commits up to this commit.
 func (c *Commit) CommitsCount(opts ...RevListCountOptions) (int64, error) {
 	return c.repo.RevListCount([]string{c.ID.String()}, opts...)
@@ -5,6 +5,8 @@
 package git
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -286,6 +288,51 @@ func TestCommit_SearchCommits(t *testing.T) {
 	}
 }
 
+func TestCommit_Stats(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = os.WriteFile(filepath.Join(r.Path(), "README.txt"), []byte("stats\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Add(AddOptions{All: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Commit(&Signature{Name: "alice", Email: "alice@example.com"}, "Update README"); err != nil {
+		t.Fatal(err)
+	}
+
+	commits, err := r.Log("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("latest commit", func(t *testing.T) {
+		stat, err := commits[0].Stats()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if assert.Equal(t, 1, stat.NumFiles()) {
+			assert.Equal(t, "README.txt", stat.Files[0].Path)
+			assert.Equal(t, 1, stat.Files[0].Additions)
+		}
+		assert.Equal(t, 1, stat.TotalAdditions())
+	})
+
+	t.Run("root commit", func(t *testing.T) {
+		stat, err := commits[len(commits)-1].Stats()
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.NotZero(t, stat.NumFiles())
+		assert.Zero(t, stat.TotalDeletions())
+	})
+}
+
 func TestCommit_ShowNameStatus(t *testing.T) {
 	tests := []struct {
 		id        string
@@ -0,0 +1,159 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// Trailer is a key/value pair at the end of a commit message, e.g.
+// "Signed-off-by: Alice <alice@example.com>".
+type Trailer struct {
+	// The key of the trailer, e.g. "Signed-off-by".
+	Key string
+	// The value of the trailer, e.g. "Alice <alice@example.com>".
+	Value string
+}
+
+// String returns the trailer in the form of "Key: Value".
+func (t *Trailer) String() string {
+	return t.Key + ": " + t.Value
+}
+
+// A list of well-known trailer keys.
+const (
+	TrailerSignedOffBy  = "Signed-off-by"
+	TrailerCoAuthoredBy = "Co-authored-by"
+	TrailerReviewedBy   = "Reviewed-by"
+)
+
+// parseTrailers parses the output of "git interpret-trailers --parse", which
+// has one "Key: Value" pair per line.
+func parseTrailers(data []byte) []*Trailer {
+	trailers := make([]*Trailer, 0, 3)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		i := strings.IndexByte(line, ':')
+		if i <= 0 {
+			continue
+		}
+		trailers = append(trailers, &Trailer{
+			Key:   strings.TrimSpace(line[:i]),
+			Value: strings.TrimSpace(line[i+1:]),
+		})
+	}
+	return trailers
+}
+
+// interpretTrailers runs "git interpret-trailers" in given directory with the
+// message as the input.
+func interpretTrailers(dir, message string, opt CommandOptions, args ...string) ([]byte, error) {
+	cmd := NewCommand("interpret-trailers").
+		AddOptions(opt).
+		AddArgs(args...)
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	err := cmd.RunInDirWithOptions(dir, RunInDirOptions{
+		Stdin:  strings.NewReader(message),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if err != nil {
+		return nil, concatenateError(err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// TrailersOptions contains optional arguments for parsing trailers.
+//
+// Docs: https://git-scm.com/docs/git-interpret-trailers#Documentation/git-interpret-trailers.txt---parse
+type TrailersOptions struct {
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// Trailers returns the trailers of the commit message in the order they
+// appear, as parsed by "git interpret-trailers --parse". Continuation lines of
+// a trailer are unfolded into a single line.
+func (c *Commit) Trailers(opts ...TrailersOptions) ([]*Trailer, error) {
+	var opt TrailersOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdout, err := interpretTrailers(c.repo.path, c.Message, opt.CommandOptions, "--parse")
+	if err != nil {
+		return nil, err
+	}
+	return parseTrailers(stdout), nil
+}
+
+// CoAuthors returns the co-authors of the commit listed by "Co-authored-by"
+// trailers in the form of "Name <email>". The time of returned signatures is
+// always zero.
+func (c *Commit) CoAuthors(opts ...TrailersOptions) ([]*Signature, error) {
+	trailers, err := c.Trailers(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	coAuthors := make([]*Signature, 0, len(trailers))
+	for _, t := range trailers {
+		if !strings.EqualFold(t.Key, TrailerCoAuthoredBy) {
+			continue
+		}
+
+		name, email, _, ok := parseMailmapIdentity(t.Value)
+		if !ok {
+			continue
+		}
+		coAuthors = append(coAuthors, &Signature{
+			Name:  name,
+			Email: email,
+		})
+	}
+	return coAuthors, nil
+}
+
+// AddTrailersOptions contains optional arguments for adding trailers to a
+// message.
+//
+// Docs: https://git-scm.com/docs/git-interpret-trailers
+type AddTrailersOptions struct {
+	// Indicates whether to replace the existing trailer with the same key. By
+	// default, a trailer is added unless the exact same trailer already exists.
+	Replace bool
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// AddTrailers adds given trailers to the end of the message, and returns the
+// new message. It is useful to compose messages for creating commits. The
+// "trailer.*" configuration of the repository is respected.
+func (r *Repository) AddTrailers(message string, trailers []*Trailer, opts ...AddTrailersOptions) (string, error) {
+	var opt AddTrailersOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	ifExists := "addIfDifferent"
+	if opt.Replace {
+		ifExists = "replace"
+	}
+	args := []string{"--where", "end", "--if-exists", ifExists, "--if-missing", "add"}
+	for _, t := range trailers {
+		args = append(args, "--trailer", t.String())
+	}
+
+	stdout, err := interpretTrailers(r.path, message, opt.CommandOptions, args...)
+	if err != nil {
+		return "", err
+	}
+	return string(stdout), nil
+}
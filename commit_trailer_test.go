@@ -0,0 +1,154 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseTrailers(t *testing.T) {
+	got := parseTrailers([]byte(`Signed-off-by: Alice <alice@example.com>
+Co-authored-by: Bob <bob@example.com>
+Fixes: #123
+Acked-by:
+not a trailer
+`))
+	want := []*Trailer{
+		{Key: "Signed-off-by", Value: "Alice <alice@example.com>"},
+		{Key: "Co-authored-by", Value: "Bob <bob@example.com>"},
+		{Key: "Fixes", Value: "#123"},
+		{Key: "Acked-by", Value: ""},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestCommit_Trailers(t *testing.T) {
+	c := &Commit{
+		Message: `Fix the bug
+
+The bug is really
+annoying.
+
+Signed-off-by: Alice <alice@example.com>
+Co-authored-by: Bob <bob@example.com>
+Reviewed-by: Carol
+ <carol@example.com>
+co-authored-by: Dave <dave@example.com>
+Co-authored-by: invalid
+`,
+		Tree: &Tree{
+			repo: testrepo,
+		},
+	}
+
+	trailers, err := c.Trailers()
+	require.NoError(t, err)
+	want := []*Trailer{
+		{Key: TrailerSignedOffBy, Value: "Alice <alice@example.com>"},
+		{Key: TrailerCoAuthoredBy, Value: "Bob <bob@example.com>"},
+		{Key: TrailerReviewedBy, Value: "Carol <carol@example.com>"},
+		{Key: "co-authored-by", Value: "Dave <dave@example.com>"},
+		{Key: TrailerCoAuthoredBy, Value: "invalid"},
+	}
+	assert.Equal(t, want, trailers)
+
+	coAuthors, err := c.CoAuthors()
+	require.NoError(t, err)
+	assert.Equal(t, []*Signature{
+		{Name: "Bob", Email: "bob@example.com"},
+		{Name: "Dave", Email: "dave@example.com"},
+	}, coAuthors)
+
+	t.Run("no trailers", func(t *testing.T) {
+		c := &Commit{
+			Message: "Fix the bug\n\nSigned-off-by is mentioned but not a trailer.\n",
+			Tree: &Tree{
+				repo: testrepo,
+			},
+		}
+		trailers, err := c.Trailers()
+		require.NoError(t, err)
+		assert.Empty(t, trailers)
+	})
+}
+
+func TestRepository_AddTrailers(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		trailers []*Trailer
+		opt      AddTrailersOptions
+		want     string
+	}{
+		{
+			name:    "no existing trailers",
+			message: "Fix the bug\n",
+			trailers: []*Trailer{
+				{Key: TrailerSignedOffBy, Value: "Alice <alice@example.com>"},
+				{Key: TrailerCoAuthoredBy, Value: "Bob <bob@example.com>"},
+			},
+			want: `Fix the bug
+
+Signed-off-by: Alice <alice@example.com>
+Co-authored-by: Bob <bob@example.com>
+`,
+		},
+		{
+			name: "skip the exact same trailer",
+			message: `Fix the bug
+
+Signed-off-by: Alice <alice@example.com>
+`,
+			trailers: []*Trailer{
+				{Key: TrailerSignedOffBy, Value: "Alice <alice@example.com>"},
+				{Key: TrailerSignedOffBy, Value: "Bob <bob@example.com>"},
+			},
+			want: `Fix the bug
+
+Signed-off-by: Alice <alice@example.com>
+Signed-off-by: Bob <bob@example.com>
+`,
+		},
+		{
+			name: "replace",
+			message: `Fix the bug
+
+Reviewed-by: Alice <alice@example.com>
+Signed-off-by: Alice <alice@example.com>
+`,
+			trailers: []*Trailer{
+				{Key: TrailerReviewedBy, Value: "Bob <bob@example.com>"},
+			},
+			opt: AddTrailersOptions{Replace: true},
+			want: `Fix the bug
+
+Signed-off-by: Alice <alice@example.com>
+Reviewed-by: Bob <bob@example.com>
+`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := testrepo.AddTrailers(test.message, test.trailers, test.opt)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+	t.Run("repository config", func(t *testing.T) {
+		r, cleanup, err := setupTempRepo()
+		require.NoError(t, err)
+		defer cleanup()
+
+		_, err = NewCommand("config", "trailer.sign.key", TrailerSignedOffBy).RunInDir(r.Path())
+		require.NoError(t, err)
+
+		got, err := r.AddTrailers("Fix the bug\n", []*Trailer{{Key: "sign", Value: "Alice <alice@example.com>"}})
+		require.NoError(t, err)
+		assert.Equal(t, "Fix the bug\n\nSigned-off-by: Alice <alice@example.com>\n", got)
+	})
+}
@@ -35,6 +35,7 @@ const (
 	DiffFileChange
 	DiffFileDelete
 	DiffFileRename
+	DiffFileCopy
 )
 
 // DiffLine represents a line in diff.
@@ -43,6 +44,22 @@ type DiffLine struct {
 	Content   string       // The content of the line
 	LeftLine  int          // The left line number
 	RightLine int          // The right line number
+	Changes   []DiffSpan   // The intra-line changes, see DiffSection.ComputeInlineChanges
+
+	// The following fields are only set for combined diff.
+
+	ParentLines []int          // The line number in each parent, 0 if the line is absent in the parent
+	ParentTypes []DiffLineType // The marker of the line for each parent, i.e. add, delete or plain
+
+	noNewline bool // Whether the line is followed by "\ No newline at end of file"
+}
+
+// markerLen returns the length of the leading markers of the content.
+func (l *DiffLine) markerLen() int {
+	if n := len(l.ParentTypes); n > 0 {
+		return n
+	}
+	return 1
 }
 
 // DiffSection represents a section in diff.
@@ -101,6 +118,76 @@ loop:
 	return nil
 }
 
+// parseHunkRange parses a range of a section header, e.g. "-1,3" or "+1", and
+// returns the first line number and the number of lines. The first line number
+// is the one right after the range when the range is empty.
+func parseHunkRange(s string) (first, count int, err error) {
+	if len(s) < 2 {
+		return 0, 0, fmt.Errorf("malformed range: %q", s)
+	}
+	s = s[1:]
+
+	count = 1
+	if i := strings.IndexByte(s, ','); i >= 0 {
+		count, err = strconv.Atoi(s[i+1:])
+		if err != nil {
+			return 0, 0, fmt.Errorf("parse count: %v", err)
+		}
+		s = s[:i]
+	}
+	first, err = strconv.Atoi(s)
+	if err != nil {
+		return 0, 0, fmt.Errorf("parse line: %v", err)
+	}
+	if count == 0 {
+		first++
+	}
+	return first, count, nil
+}
+
+// hunkRange returns the first line numbers and the number of lines of both
+// sides of the section, and the text after the section header, e.g. the
+// function name.
+func (s *DiffSection) hunkRange() (leftFirst, leftCount, rightFirst, rightCount int, suffix string, err error) {
+	if len(s.Lines) == 0 || s.Lines[0].Type != DiffLineSection {
+		return 0, 0, 0, 0, "", errors.New("missing section header")
+	}
+
+	// e.g. @@ -1,3 +1,4 @@ func main() {
+	header := s.Lines[0].Content
+	fields := strings.SplitN(header, " ", 5)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" {
+		return 0, 0, 0, 0, "", fmt.Errorf("malformed section header: %q", header)
+	}
+	leftFirst, leftCount, err = parseHunkRange(fields[1])
+	if err != nil {
+		return 0, 0, 0, 0, "", err
+	}
+	rightFirst, rightCount, err = parseHunkRange(fields[2])
+	if err != nil {
+		return 0, 0, 0, 0, "", err
+	}
+	if len(fields) == 5 {
+		suffix = " " + fields[4]
+	}
+	return leftFirst, leftCount, rightFirst, rightCount, suffix, nil
+}
+
+// formatHunkHeader returns the section header of given ranges, which is the
+// reverse of DiffSection.hunkRange.
+func formatHunkHeader(leftFirst, leftCount, rightFirst, rightCount int, suffix string) string {
+	format := func(first, count int) string {
+		switch count {
+		case 0:
+			return strconv.Itoa(first-1) + ",0"
+		case 1:
+			return strconv.Itoa(first)
+		}
+		return strconv.Itoa(first) + "," + strconv.Itoa(count)
+	}
+	return "@@ -" + format(leftFirst, leftCount) + " +" + format(rightFirst, rightCount) + " @@" + suffix
+}
+
 // DiffFile represents a file in diff.
 type DiffFile struct {
 	// The name of the file.
@@ -112,20 +199,26 @@ type DiffFile struct {
 	Index string
 	// OldIndex is the old index (SHA1 hash) of the file.
 	OldIndex string
+	// ParentIndexes are the indexes (SHA1 hash) of the file in each parent,
+	// only set for combined diff.
+	ParentIndexes []string
 	// The sections in the file.
 	Sections []*DiffSection
 
 	numAdditions int
 	numDeletions int
 
-	oldName string
+	oldName    string
+	similarity int
 
-	mode    EntryMode
-	oldMode EntryMode
+	mode        EntryMode
+	oldMode     EntryMode
+	parentModes []EntryMode
 
 	isBinary     bool
 	isSubmodule  bool
 	isIncomplete bool
+	isCombined   bool
 }
 
 // NumSections returns the number of sections in the file.
@@ -158,7 +251,13 @@ func (f *DiffFile) IsRenamed() bool {
 	return f.Type == DiffFileRename
 }
 
-// OldName returns previous name before renaming.
+// IsCopied returns true if the file has been copied from another file.
+func (f *DiffFile) IsCopied() bool {
+	return f.Type == DiffFileCopy
+}
+
+// OldName returns previous name before renaming, or the name of the source
+// file of copying.
 func (f *DiffFile) OldName() string {
 	return f.oldName
 }
@@ -188,6 +287,25 @@ func (f *DiffFile) IsIncomplete() bool {
 	return f.isIncomplete
 }
 
+// IsCombined returns true if the file is in a combined diff of a merge commit
+// against all of its parents.
+func (f *DiffFile) IsCombined() bool {
+	return f.isCombined
+}
+
+// NumParents returns the number of parents of the combined diff. It returns 0
+// if the file is not in a combined diff.
+func (f *DiffFile) NumParents() int {
+	return len(f.ParentIndexes)
+}
+
+// ParentModes returns the mode of the file in each parent of the combined diff
+// if they are different from the mode of the result. It returns nil if the
+// mode is unchanged or the file is not in a combined diff.
+func (f *DiffFile) ParentModes() []EntryMode {
+	return f.parentModes
+}
+
 // Diff represents a Git diff.
 type Diff struct {
 	Files []*DiffFile // The files in the diff
@@ -278,8 +396,8 @@ func (p *diffParser) parseFileHeader() (*DiffFile, error) {
 	a := line[beg+2 : middle]
 	b := line[middle+3:]
 	if hasQuote {
-		a = string(UnescapeChars([]byte(a[1 : len(a)-1])))
-		b = string(UnescapeChars([]byte(b[1 : len(b)-1])))
+		a = unquotePath(`"` + a[1:])
+		b = unquotePath(`"` + b[1:])
 	}
 
 	file := &DiffFile{
@@ -345,9 +463,12 @@ checkType:
 			file.Type = DiffFileRename
 			file.oldName = a
 			file.Name = b
-
-			// No need to look for index if it's a pure rename
-			if strings.HasSuffix(line, "100%") {
+			file.similarity, _ = strconv.Atoi(strings.TrimSuffix(line[len("similarity index "):], "%"))
+		case strings.HasPrefix(line, "copy from "):
+			file.Type = DiffFileCopy
+		case strings.HasPrefix(line, "rename to "), strings.HasPrefix(line, "copy to "):
+			// No need to look for index if it's a pure rename or copy
+			if file.similarity == 100 {
 				break checkType
 			}
 		case strings.HasPrefix(line, "new mode"):
@@ -381,15 +502,19 @@ func (p *diffParser) parseSection() (_ *DiffSection, isIncomplete bool, _ error)
 		},
 	}
 
-	// Parse line number, e.g. @@ -0,0 +1,3 @@
-	var leftLine, rightLine int
+	// Parse line number, e.g. @@ -0,0 +1,3 @@. Sections of a range-diff are
+	// labeled instead (e.g. @@ main.go), whose lines are numbered from the start
+	// of the section.
+	leftLine, rightLine := 1, 1
 	ss := strings.Split(line, "@@")
-	ranges := strings.Split(ss[1][1:], " ")
-	leftLine, _ = strconv.Atoi(strings.Split(ranges[0], ",")[0][1:])
-	if len(ranges) > 1 {
-		rightLine, _ = strconv.Atoi(strings.Split(ranges[1], ",")[0])
-	} else {
-		rightLine = leftLine
+	if len(ss) > 1 && strings.HasPrefix(ss[1], " -") {
+		ranges := strings.Split(ss[1][1:], " ")
+		leftLine, _ = strconv.Atoi(strings.Split(ranges[0], ",")[0][1:])
+		if len(ranges) > 1 {
+			rightLine, _ = strconv.Atoi(strings.Split(ranges[1], ",")[0])
+		} else {
+			rightLine = leftLine
+		}
 	}
 
 	var err error
@@ -411,6 +536,9 @@ func (p *diffParser) parseSection() (_ *DiffSection, isIncomplete bool, _ error)
 			// No new line indicator
 			if p.buffer[0] == '\\' &&
 				bytes.HasPrefix(p.buffer, []byte(`\ No newline at end of file`)) {
+				if n := len(section.Lines); n > 1 {
+					section.Lines[n-1].noNewline = true
+				}
 				p.buffer = nil
 				continue
 			}
@@ -459,6 +587,241 @@ func (p *diffParser) parseSection() (_ *DiffSection, isIncomplete bool, _ error)
 	return section, false, nil
 }
 
+var (
+	diffCombinedHead = []byte("diff --combined ")
+	diffCCHead       = []byte("diff --cc ")
+)
+
+// parseModes parses comma-separated octal modes, e.g. "100644,100755".
+func parseModes(s string) []EntryMode {
+	fields := strings.Split(s, ",")
+	modes := make([]EntryMode, len(fields))
+	for i := range fields {
+		mode, _ := strconv.ParseUint(fields[i], 8, 64)
+		modes[i] = EntryMode(mode)
+	}
+	return modes
+}
+
+// parseCombinedFileHeader parses the header of a file in combined diff, e.g.
+//
+//	diff --cc file.txt
+//	index 0c2a2c3,de4f1a1..a8f3d40
+//	mode 100644,100644..100755
+func (p *diffParser) parseCombinedFileHeader() (*DiffFile, error) {
+	line := string(p.buffer)
+	p.buffer = nil
+
+	var name string
+	if strings.HasPrefix(line, string(diffCCHead)) {
+		name = line[len(diffCCHead):]
+	} else {
+		name = line[len(diffCombinedHead):]
+	}
+	name = unquotePath(name)
+
+	file := &DiffFile{
+		Name:       name,
+		oldName:    name,
+		Type:       DiffFileChange,
+		isCombined: true,
+	}
+
+	// Unlike the normal diff, the mode line comes after the index line, thus
+	// keep reading until the start of the content.
+	for !p.isEOF {
+		if err := p.readLine(); err != nil {
+			return nil, err
+		}
+
+		line := string(p.buffer)
+		if len(line) == 0 {
+			p.buffer = nil
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "index "): // e.g. index 0c2a2c3,de4f1a1..a8f3d40
+			fields := strings.Fields(line[6:])
+			i := -1
+			if len(fields) > 0 {
+				i = strings.LastIndex(fields[0], "..")
+			}
+			if i < 0 {
+				return nil, errors.New("malformed index: expect SHAs in the form of <parent>,<parent>..<new>")
+			}
+			file.ParentIndexes = strings.Split(fields[0][:i], ",")
+			file.OldIndex = file.ParentIndexes[0]
+			file.Index = fields[0][i+2:]
+			if len(fields) > 1 {
+				mode, _ := strconv.ParseUint(fields[1], 8, 64)
+				file.mode = EntryMode(mode)
+				file.oldMode = EntryMode(mode)
+			}
+		case strings.HasPrefix(line, "mode "): // e.g. mode 100644,100644..100755
+			modes := line[5:]
+			i := strings.LastIndex(modes, "..")
+			if i < 0 {
+				break
+			}
+			file.parentModes = parseModes(modes[:i])
+			file.oldMode = file.parentModes[0]
+			file.mode = parseModes(modes[i+2:])[0]
+		case strings.HasPrefix(line, "new file mode "):
+			file.Type = DiffFileAdd
+			file.mode = parseModes(line[len("new file mode "):])[0]
+			file.oldMode = file.mode
+		case strings.HasPrefix(line, "deleted file mode "):
+			file.Type = DiffFileDelete
+			file.parentModes = parseModes(line[len("deleted file mode "):])
+			file.mode = file.parentModes[0]
+			file.oldMode = file.mode
+		default:
+			// The start of the content or the next file
+			file.isSubmodule = file.mode == EntryCommit || file.oldMode == EntryCommit
+			return file, nil
+		}
+		p.buffer = nil
+	}
+
+	file.isSubmodule = file.mode == EntryCommit || file.oldMode == EntryCommit
+	return file, nil
+}
+
+// parseCombinedSection parses a section of combined diff, which has a column
+// of markers for each parent, e.g.
+//
+//	@@@ -1,4 -1,3 +1,4 @@@
+//	  a
+//	- B master
+//	 -B side
+//	++B merged
+func (p *diffParser) parseCombinedSection() (_ *DiffSection, isIncomplete bool, _ error) {
+	line := string(p.buffer)
+	p.buffer = nil
+
+	section := &DiffSection{
+		Lines: []*DiffLine{
+			{
+				Type:    DiffLineSection,
+				Content: line,
+			},
+		},
+	}
+
+	// Parse line numbers, e.g. @@@ -1,4 -1,3 +1,4 @@@, the number of "@" is the
+	// number of parents plus one.
+	numParents := 0
+	for numParents < len(line) && line[numParents] == '@' {
+		numParents++
+	}
+	numParents--
+	if numParents < 2 {
+		return nil, false, fmt.Errorf("malformed combined section header: %q", line)
+	}
+
+	parentLines := make([]int, numParents)
+	rightLine := 0
+	ranges := strings.Fields(line[numParents+1:])
+	for i := 0; i < len(ranges) && i <= numParents; i++ {
+		start, _ := strconv.Atoi(strings.Split(ranges[i][1:], ",")[0])
+		if i < numParents {
+			parentLines[i] = start
+		} else {
+			rightLine = start
+		}
+	}
+
+	isMarkers := func(line []byte) bool {
+		if len(line) < numParents {
+			return false
+		}
+		for _, c := range line[:numParents] {
+			if c != ' ' && c != '+' && c != '-' {
+				return false
+			}
+		}
+		return true
+	}
+
+	var err error
+	for !p.isEOF {
+		if err = p.readLine(); err != nil {
+			return nil, false, err
+		}
+
+		if len(p.buffer) == 0 {
+			p.buffer = nil
+			continue
+		}
+
+		// Make sure we're still in the section. If not, we're done with this section.
+		if !isMarkers(p.buffer) {
+			// No new line indicator
+			if p.buffer[0] == '\\' &&
+				bytes.HasPrefix(p.buffer, []byte(`\ No newline at end of file`)) {
+				if n := len(section.Lines); n > 1 {
+					section.Lines[n-1].noNewline = true
+				}
+				p.buffer = nil
+				continue
+			}
+			return section, false, nil
+		}
+
+		line := string(p.buffer)
+		p.buffer = nil
+
+		// Too many characters in a single diff line
+		if p.maxLineChars > 0 && len(line) > p.maxLineChars {
+			return section, true, nil
+		}
+
+		markers := line[:numParents]
+		isDelete := strings.IndexByte(markers, '-') >= 0
+		diffLine := &DiffLine{
+			Type:        DiffLinePlain,
+			Content:     line,
+			ParentLines: make([]int, numParents),
+			ParentTypes: make([]DiffLineType, numParents),
+		}
+		for i := 0; i < numParents; i++ {
+			switch markers[i] {
+			case '+':
+				diffLine.ParentTypes[i] = DiffLineAdd
+			case '-':
+				diffLine.ParentTypes[i] = DiffLineDelete
+			default:
+				diffLine.ParentTypes[i] = DiffLinePlain
+			}
+
+			// A removed line only exists in the parents that are marked with "-".
+			if markers[i] == '-' || (markers[i] == ' ' && !isDelete) {
+				diffLine.ParentLines[i] = parentLines[i]
+				parentLines[i]++
+			}
+		}
+		diffLine.LeftLine = diffLine.ParentLines[0]
+
+		switch {
+		case isDelete:
+			diffLine.Type = DiffLineDelete
+			section.numDeletions++
+		case strings.IndexByte(markers, '+') >= 0:
+			diffLine.Type = DiffLineAdd
+			section.numAdditions++
+			diffLine.RightLine = rightLine
+			rightLine++
+		default:
+			diffLine.RightLine = rightLine
+			rightLine++
+		}
+		section.Lines = append(section.Lines, diffLine)
+	}
+
+	return section, false, nil
+}
+
 func (p *diffParser) parse() (*Diff, error) {
 	diff := new(Diff)
 	file := new(DiffFile)
@@ -496,6 +859,25 @@ func (p *diffParser) parse() (*Diff, error) {
 			continue
 		}
 
+		// Found new file of combined diff
+		if bytes.HasPrefix(p.buffer, diffCombinedHead) || bytes.HasPrefix(p.buffer, diffCCHead) {
+			// Check if reached maximum number of files
+			if p.maxFiles > 0 && len(diff.Files) >= p.maxFiles {
+				diff.isIncomplete = true
+				_, _ = io.Copy(ioutil.Discard, p)
+				break
+			}
+
+			file, err = p.parseCombinedFileHeader()
+			if err != nil {
+				return nil, err
+			}
+			diff.Files = append(diff.Files, file)
+
+			currentFileLines = 0
+			continue
+		}
+
 		if file == nil || file.isIncomplete {
 			p.buffer = nil
 			continue
@@ -520,7 +902,15 @@ func (p *diffParser) parse() (*Diff, error) {
 			continue
 		}
 
-		section, isIncomplete, err := p.parseSection()
+		var (
+			section      *DiffSection
+			isIncomplete bool
+		)
+		if file.isCombined {
+			section, isIncomplete, err = p.parseCombinedSection()
+		} else {
+			section, isIncomplete, err = p.parseSection()
+		}
 		if err != nil {
 			return nil, err
 		}
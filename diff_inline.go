@@ -0,0 +1,237 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// DiffInlineMode is the granularity of intra-line changes.
+type DiffInlineMode uint8
+
+// A list of granularities of intra-line changes.
+const (
+	// DiffInlineWord compares lines by words, whitespace and punctuation.
+	DiffInlineWord DiffInlineMode = iota + 1
+	// DiffInlineChar compares lines by characters.
+	DiffInlineChar
+)
+
+// maxInlineDiffTokens is the maximum number of tokens of a line to compute
+// intra-line changes, lines that have more tokens are not highlighted.
+const maxInlineDiffTokens = 500
+
+// DiffSpan is a range of changed content within a DiffLine.
+type DiffSpan struct {
+	// The start byte offset in the content of the line, inclusive.
+	Start int
+	// The end byte offset in the content of the line, exclusive.
+	End int
+}
+
+// inlineToken is a token of a line with its byte offsets in the line.
+type inlineToken struct {
+	text  string
+	start int
+	end   int
+}
+
+// tokenizeInline splits the line into tokens by given mode. In word mode,
+// consecutive letters, digits and underscores form a word, consecutive
+// whitespace forms a token, and any other character is a token on its own.
+func tokenizeInline(line string, mode DiffInlineMode) []inlineToken {
+	class := func(r rune) int {
+		switch {
+		case r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			return 1
+		case unicode.IsSpace(r):
+			return 2
+		}
+		return 0
+	}
+
+	tokens := make([]inlineToken, 0, len(line)/2+1)
+	for i := 0; i < len(line); {
+		r, size := utf8.DecodeRuneInString(line[i:])
+		end := i + size
+		if mode == DiffInlineWord {
+			if c := class(r); c != 0 {
+				for end < len(line) {
+					next, size := utf8.DecodeRuneInString(line[end:])
+					if class(next) != c {
+						break
+					}
+					end += size
+				}
+			}
+		}
+		tokens = append(tokens, inlineToken{text: line[i:end], start: i, end: end})
+		i = end
+	}
+	return tokens
+}
+
+// diffTokens computes the shortest edit script between two token sequences
+// using the Myers' algorithm, and reports whether each token is deleted from a
+// or added to b.
+func diffTokens(a, b []inlineToken) (deleted, added []bool) {
+	n, m := len(a), len(b)
+	deleted = make([]bool, n)
+	added = make([]bool, m)
+
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	// The snapshot of v at the start of each round, only the range of diagonals
+	// [-d-1, d+1] is kept, which is all that is needed for backtracking.
+	trace := make([][]int, 0, 8)
+
+	var d int
+search:
+	for d = 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Insertion
+			} else {
+				x = v[offset+k-1] + 1 // Deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x].text == b[y].text {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	x, y := n, m
+	for ; d > 0; d-- {
+		snapshot := trace[d]
+		at := func(k int) int { return snapshot[k+d+1] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+		}
+		if x == prevX {
+			added[prevY] = true
+		} else {
+			deleted[prevX] = true
+		}
+		x, y = prevX, prevY
+	}
+	return deleted, added
+}
+
+// inlineSpans merges consecutive changed tokens into spans, and shifts them by
+// given offset.
+func inlineSpans(tokens []inlineToken, changed []bool, offset int) []DiffSpan {
+	var spans []DiffSpan
+	for i, tok := range tokens {
+		if !changed[i] {
+			continue
+		}
+		if n := len(spans); n > 0 && spans[n-1].End == tok.start+offset {
+			spans[n-1].End = tok.end + offset
+			continue
+		}
+		spans = append(spans, DiffSpan{Start: tok.start + offset, End: tok.end + offset})
+	}
+	return spans
+}
+
+// computeInlineChanges computes the intra-line changes between the deleted and
+// the added line, and sets them to both lines.
+func computeInlineChanges(del, add *DiffLine, mode DiffInlineMode) {
+	del.Changes = nil
+	add.Changes = nil
+
+	// The content starts with the "-" or "+" markers.
+	delMarkers, addMarkers := del.markerLen(), add.markerLen()
+	if len(del.Content) < delMarkers || len(add.Content) < addMarkers {
+		return
+	}
+	a := tokenizeInline(del.Content[delMarkers:], mode)
+	b := tokenizeInline(add.Content[addMarkers:], mode)
+	if len(a) == 0 || len(b) == 0 || len(a) > maxInlineDiffTokens || len(b) > maxInlineDiffTokens {
+		return
+	}
+
+	deleted, added := diffTokens(a, b)
+
+	// Highlighting is pointless when the lines have nothing in common.
+	unchanged := 0
+	for _, d := range deleted {
+		if !d {
+			unchanged++
+		}
+	}
+	if unchanged == 0 {
+		return
+	}
+
+	del.Changes = inlineSpans(a, deleted, delMarkers)
+	add.Changes = inlineSpans(b, added, addMarkers)
+}
+
+// ComputeInlineChanges pairs deleted and added lines in the section and sets
+// their intra-line changes by given mode. Within each block of consecutive
+// deleted lines followed by added lines, the n-th deleted line is paired with
+// the n-th added line. Lines that are not paired, have nothing in common with
+// their counterparts or are too long are not highlighted.
+func (s *DiffSection) ComputeInlineChanges(mode DiffInlineMode) {
+	for i := 0; i < len(s.Lines); {
+		if s.Lines[i].Type != DiffLineDelete {
+			i++
+			continue
+		}
+
+		delStart := i
+		for i < len(s.Lines) && s.Lines[i].Type == DiffLineDelete {
+			i++
+		}
+		addStart := i
+		for i < len(s.Lines) && s.Lines[i].Type == DiffLineAdd {
+			i++
+		}
+
+		dels := s.Lines[delStart:addStart]
+		adds := s.Lines[addStart:i]
+		for j := 0; j < len(dels) && j < len(adds); j++ {
+			computeInlineChanges(dels[j], adds[j], mode)
+		}
+	}
+}
+
+// ComputeInlineChanges sets intra-line changes of all sections in the file by
+// given mode.
+func (f *DiffFile) ComputeInlineChanges(mode DiffInlineMode) {
+	for _, s := range f.Sections {
+		s.ComputeInlineChanges(mode)
+	}
+}
+
+// ComputeInlineChanges sets intra-line changes of all files in the diff by
+// given mode.
+func (d *Diff) ComputeInlineChanges(mode DiffInlineMode) {
+	for _, f := range d.Files {
+		f.ComputeInlineChanges(mode)
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_tokenizeInline(t *testing.T) {
+	texts := func(tokens []inlineToken) []string {
+		ss := make([]string, len(tokens))
+		for i, tok := range tokens {
+			ss[i] = tok.text
+		}
+		return ss
+	}
+
+	line := "foo_bar(x,  42) // héllo"
+	assert.Equal(t,
+		[]string{"foo_bar", "(", "x", ",", "  ", "42", ")", " ", "/", "/", " ", "héllo"},
+		texts(tokenizeInline(line, DiffInlineWord)),
+	)
+	assert.Equal(t,
+		[]string{"h", "é", "l"},
+		texts(tokenizeInline("hél", DiffInlineChar)),
+	)
+
+	// Offsets must point back to the line
+	for _, tok := range tokenizeInline(line, DiffInlineWord) {
+		assert.Equal(t, tok.text, line[tok.start:tok.end])
+	}
+}
+
+func Test_diffTokens(t *testing.T) {
+	toTokens := func(s string) []inlineToken {
+		return tokenizeInline(s, DiffInlineChar)
+	}
+	lcs := func(a, b string) int {
+		dp := make([][]int, len(a)+1)
+		for i := range dp {
+			dp[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					dp[i][j] = dp[i+1][j+1] + 1
+				} else if dp[i+1][j] > dp[i][j+1] {
+					dp[i][j] = dp[i+1][j]
+				} else {
+					dp[i][j] = dp[i][j+1]
+				}
+			}
+		}
+		return dp[0][0]
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	randString := func() string {
+		b := make([]byte, rnd.Intn(12))
+		for i := range b {
+			b[i] = "abc"[rnd.Intn(3)]
+		}
+		return string(b)
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := randString(), randString()
+		deleted, added := diffTokens(toTokens(a), toTokens(b))
+
+		// The unchanged tokens of both sides must be the same sequence, and it must
+		// be the longest common subsequence.
+		var keptA, keptB []byte
+		for j := range a {
+			if !deleted[j] {
+				keptA = append(keptA, a[j])
+			}
+		}
+		for j := range b {
+			if !added[j] {
+				keptB = append(keptB, b[j])
+			}
+		}
+		assert.Equal(t, string(keptA), string(keptB), "a=%q b=%q", a, b)
+		assert.Equal(t, lcs(a, b), len(keptA), "a=%q b=%q", a, b)
+	}
+}
+
+func TestDiffSection_ComputeInlineChanges(t *testing.T) {
+	section := &DiffSection{
+		Lines: []*DiffLine{
+			{Type: DiffLineSection, Content: "@@ -1,6 +1,6 @@"},
+			{Type: DiffLinePlain, Content: " package main"},
+			{Type: DiffLineDelete, Content: `-	fmt.Println("Hello world")`},
+			{Type: DiffLineDelete, Content: "-	return nil"},
+			{Type: DiffLineDelete, Content: "-	// removed"},
+			{Type: DiffLineAdd, Content: `+	fmt.Println("Hello, Gopher")`},
+			{Type: DiffLineAdd, Content: "+	return err"},
+			{Type: DiffLinePlain, Content: " }"},
+			{Type: DiffLineDelete, Content: "-abc"},
+			{Type: DiffLineAdd, Content: "+xyz"},
+			{Type: DiffLineAdd, Content: "+added only"},
+		},
+	}
+
+	section.ComputeInlineChanges(DiffInlineWord)
+
+	spans := func(l *DiffLine) []string {
+		var ss []string
+		for _, span := range l.Changes {
+			ss = append(ss, l.Content[span.Start:span.End])
+		}
+		return ss
+	}
+	assert.Equal(t, []string{"world"}, spans(section.Lines[2]))
+	assert.Equal(t, []string{",", "Gopher"}, spans(section.Lines[5]))
+	assert.Equal(t, []string{"nil"}, spans(section.Lines[3]))
+	assert.Equal(t, []string{"err"}, spans(section.Lines[6]))
+
+	// Unpaired lines, and lines that have nothing in common
+	assert.Nil(t, section.Lines[4].Changes)
+	assert.Nil(t, section.Lines[8].Changes)
+	assert.Nil(t, section.Lines[9].Changes)
+	assert.Nil(t, section.Lines[10].Changes)
+	assert.Nil(t, section.Lines[1].Changes)
+
+	t.Run("char mode", func(t *testing.T) {
+		section := &DiffSection{
+			Lines: []*DiffLine{
+				{Type: DiffLineDelete, Content: "-colour"},
+				{Type: DiffLineAdd, Content: "+color"},
+			},
+		}
+		section.ComputeInlineChanges(DiffInlineChar)
+		assert.Equal(t, []DiffSpan{{Start: 5, End: 6}}, section.Lines[0].Changes)
+		assert.Nil(t, section.Lines[1].Changes)
+	})
+}
@@ -0,0 +1,498 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DiffRenderer renders a diff to a writer.
+type DiffRenderer interface {
+	// Render writes the diff to the writer.
+	Render(w io.Writer, diff *Diff) error
+}
+
+var (
+	_ DiffRenderer = (*UnifiedDiffRenderer)(nil)
+	_ DiffRenderer = (*JSONDiffRenderer)(nil)
+	_ DiffRenderer = (*HTMLDiffRenderer)(nil)
+)
+
+// UnifiedDiffRenderer renders a diff as a patch in the unified format of "git
+// diff", which can be applied by "git apply". Paths are quoted in the same way
+// as Git when they contain special characters.
+type UnifiedDiffRenderer struct{}
+
+// Render writes the diff to the writer as a unified patch.
+func (*UnifiedDiffRenderer) Render(w io.Writer, diff *Diff) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range diff.Files {
+		writeUnifiedFile(bw, f)
+	}
+	return bw.Flush()
+}
+
+func writeUnifiedFile(w *bufio.Writer, f *DiffFile) {
+	if f.isCombined {
+		writeCombinedFile(w, f)
+		return
+	}
+
+	oldName, newName := f.oldName, f.Name
+	if oldName == "" {
+		oldName = newName
+	}
+	_, _ = fmt.Fprintf(w, "diff --git %s %s\n", quotePath("a/"+oldName), quotePath("b/"+newName))
+
+	modeSuffix := ""
+	switch f.Type {
+	case DiffFileAdd:
+		_, _ = fmt.Fprintf(w, "new file mode %06o\n", f.mode)
+	case DiffFileDelete:
+		_, _ = fmt.Fprintf(w, "deleted file mode %06o\n", f.mode)
+	default:
+		if f.oldMode != 0 && f.mode != 0 && f.oldMode != f.mode {
+			_, _ = fmt.Fprintf(w, "old mode %06o\nnew mode %06o\n", f.oldMode, f.mode)
+		} else if f.mode != 0 {
+			modeSuffix = fmt.Sprintf(" %06o", f.mode)
+		}
+	}
+	if f.Type == DiffFileRename {
+		if f.similarity > 0 {
+			_, _ = fmt.Fprintf(w, "similarity index %d%%\n", f.similarity)
+		}
+		_, _ = fmt.Fprintf(w, "rename from %s\nrename to %s\n", quotePath(oldName), quotePath(newName))
+	} else if f.Type == DiffFileCopy {
+		if f.similarity > 0 {
+			_, _ = fmt.Fprintf(w, "similarity index %d%%\n", f.similarity)
+		}
+		_, _ = fmt.Fprintf(w, "copy from %s\ncopy to %s\n", quotePath(oldName), quotePath(newName))
+	}
+	if f.Index != "" || f.OldIndex != "" {
+		_, _ = fmt.Fprintf(w, "index %s..%s%s\n", f.OldIndex, f.Index, modeSuffix)
+	}
+
+	oldPath, newPath := quotePath("a/"+oldName), quotePath("b/"+newName)
+	switch f.Type {
+	case DiffFileAdd:
+		oldPath = "/dev/null"
+	case DiffFileDelete:
+		newPath = "/dev/null"
+	}
+	if f.isBinary {
+		_, _ = fmt.Fprintf(w, "Binary files %s and %s differ\n", oldPath, newPath)
+		return
+	}
+	if len(f.Sections) == 0 {
+		return
+	}
+
+	writeUnifiedFileLine(w, "--- ", oldPath)
+	writeUnifiedFileLine(w, "+++ ", newPath)
+	writeUnifiedSections(w, f.Sections)
+}
+
+// writeUnifiedFileLine writes the "---" or "+++" line of the path. Like Git, a
+// tab is appended when the path contains spaces to make the end unambiguous.
+func writeUnifiedFileLine(w *bufio.Writer, prefix, path string) {
+	_, _ = w.WriteString(prefix + path)
+	if strings.Contains(path, " ") {
+		_ = w.WriteByte('\t')
+	}
+	_ = w.WriteByte('\n')
+}
+
+// writeUnifiedSections writes lines of sections, along with the "\ No newline
+// at end of file" markers.
+func writeUnifiedSections(w *bufio.Writer, sections []*DiffSection) {
+	for _, s := range sections {
+		for _, l := range s.Lines {
+			_, _ = w.WriteString(l.Content)
+			_ = w.WriteByte('\n')
+			if l.noNewline {
+				_, _ = w.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+}
+
+// writeCombinedFile writes the file in the format of "git diff --cc".
+func writeCombinedFile(w *bufio.Writer, f *DiffFile) {
+	_, _ = fmt.Fprintf(w, "diff --cc %s\n", quotePath(f.Name))
+	if len(f.ParentIndexes) > 0 {
+		_, _ = fmt.Fprintf(w, "index %s..%s\n", strings.Join(f.ParentIndexes, ","), f.Index)
+	}
+
+	formatModes := func(modes []EntryMode) string {
+		ss := make([]string, len(modes))
+		for i := range modes {
+			ss[i] = fmt.Sprintf("%06o", modes[i])
+		}
+		return strings.Join(ss, ",")
+	}
+	oldPath, newPath := quotePath("a/"+f.Name), quotePath("b/"+f.Name)
+	switch {
+	case f.Type == DiffFileAdd:
+		_, _ = fmt.Fprintf(w, "new file mode %06o\n", f.mode)
+		oldPath = "/dev/null"
+	case f.Type == DiffFileDelete:
+		_, _ = fmt.Fprintf(w, "deleted file mode %s\n", formatModes(f.parentModes))
+		newPath = "/dev/null"
+	case len(f.parentModes) > 0:
+		_, _ = fmt.Fprintf(w, "mode %s..%06o\n", formatModes(f.parentModes), f.mode)
+	}
+
+	if f.isBinary {
+		_, _ = w.WriteString("Binary files differ\n")
+		return
+	}
+	if len(f.Sections) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, "--- %s\n+++ %s\n", oldPath, newPath)
+	writeUnifiedSections(w, f.Sections)
+}
+
+// SideBySideRow is a row of the side-by-side view of a diff. A side is nil
+// when there is no corresponding line on that side.
+type SideBySideRow struct {
+	// The line on the left (old) side.
+	Left *DiffLine
+	// The line on the right (new) side.
+	Right *DiffLine
+}
+
+// SideBySideSection is a section of the side-by-side view of a diff.
+type SideBySideSection struct {
+	// The section header line, e.g. "@@ -1,3 +1,4 @@".
+	Header *DiffLine
+	// The rows of the section.
+	Rows []*SideBySideRow
+}
+
+// SideBySide returns the side-by-side view of the section. Plain lines appear
+// on both sides, and within each block of consecutive deleted lines followed
+// by added lines, the n-th deleted line is aligned with the n-th added line.
+func (s *DiffSection) SideBySide() *SideBySideSection {
+	view := &SideBySideSection{
+		Rows: make([]*SideBySideRow, 0, len(s.Lines)),
+	}
+	for i := 0; i < len(s.Lines); {
+		line := s.Lines[i]
+		switch line.Type {
+		case DiffLineSection:
+			view.Header = line
+			i++
+			continue
+		case DiffLinePlain:
+			view.Rows = append(view.Rows, &SideBySideRow{Left: line, Right: line})
+			i++
+			continue
+		}
+
+		delStart := i
+		for i < len(s.Lines) && s.Lines[i].Type == DiffLineDelete {
+			i++
+		}
+		addStart := i
+		for i < len(s.Lines) && s.Lines[i].Type == DiffLineAdd {
+			i++
+		}
+
+		dels := s.Lines[delStart:addStart]
+		adds := s.Lines[addStart:i]
+		for j := 0; j < len(dels) || j < len(adds); j++ {
+			row := new(SideBySideRow)
+			if j < len(dels) {
+				row.Left = dels[j]
+			}
+			if j < len(adds) {
+				row.Right = adds[j]
+			}
+			view.Rows = append(view.Rows, row)
+		}
+	}
+	return view
+}
+
+// SideBySide returns the side-by-side view of all sections in the file.
+func (f *DiffFile) SideBySide() []*SideBySideSection {
+	sections := make([]*SideBySideSection, len(f.Sections))
+	for i, s := range f.Sections {
+		sections[i] = s.SideBySide()
+	}
+	return sections
+}
+
+// String returns the name of the line type, e.g. "add".
+func (t DiffLineType) String() string {
+	switch t {
+	case DiffLinePlain:
+		return "plain"
+	case DiffLineAdd:
+		return "add"
+	case DiffLineDelete:
+		return "delete"
+	case DiffLineSection:
+		return "section"
+	}
+	return "unknown"
+}
+
+// String returns the name of the file type, e.g. "rename".
+func (t DiffFileType) String() string {
+	switch t {
+	case DiffFileAdd:
+		return "add"
+	case DiffFileChange:
+		return "change"
+	case DiffFileDelete:
+		return "delete"
+	case DiffFileRename:
+		return "rename"
+	case DiffFileCopy:
+		return "copy"
+	}
+	return "unknown"
+}
+
+type jsonDiffSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type jsonDiffLine struct {
+	Type      string         `json:"type"`
+	Content   string         `json:"content"`
+	LeftLine  int            `json:"left_line,omitempty"`
+	RightLine int            `json:"right_line,omitempty"`
+	Changes   []jsonDiffSpan `json:"changes,omitempty"`
+
+	ParentLines []int    `json:"parent_lines,omitempty"`
+	ParentTypes []string `json:"parent_types,omitempty"`
+}
+
+type jsonDiffSection struct {
+	Lines []jsonDiffLine `json:"lines"`
+}
+
+type jsonDiffFile struct {
+	Name          string            `json:"name"`
+	OldName       string            `json:"old_name,omitempty"`
+	Type          string            `json:"type"`
+	Index         string            `json:"index,omitempty"`
+	OldIndex      string            `json:"old_index,omitempty"`
+	ParentIndexes []string          `json:"parent_indexes,omitempty"`
+	Mode          string            `json:"mode,omitempty"`
+	OldMode       string            `json:"old_mode,omitempty"`
+	IsBinary      bool              `json:"is_binary"`
+	IsSubmodule   bool              `json:"is_submodule"`
+	IsIncomplete  bool              `json:"is_incomplete"`
+	IsCombined    bool              `json:"is_combined,omitempty"`
+	Additions     int               `json:"additions"`
+	Deletions     int               `json:"deletions"`
+	Sections      []jsonDiffSection `json:"sections"`
+}
+
+type jsonDiff struct {
+	Files          []jsonDiffFile `json:"files"`
+	TotalAdditions int            `json:"total_additions"`
+	TotalDeletions int            `json:"total_deletions"`
+	IsIncomplete   bool           `json:"is_incomplete"`
+}
+
+// JSONDiffRenderer renders a diff as JSON with stable field names in
+// snake_case. File modes are octal strings, e.g. "100644".
+type JSONDiffRenderer struct {
+	// The indentation of each level, the output is compact when empty.
+	Indent string
+}
+
+func formatEntryMode(mode EntryMode) string {
+	if mode == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%06o", mode)
+}
+
+// Render writes the diff to the writer as JSON.
+func (r *JSONDiffRenderer) Render(w io.Writer, diff *Diff) error {
+	out := jsonDiff{
+		Files:          make([]jsonDiffFile, 0, len(diff.Files)),
+		TotalAdditions: diff.totalAdditions,
+		TotalDeletions: diff.totalDeletions,
+		IsIncomplete:   diff.isIncomplete,
+	}
+	for _, f := range diff.Files {
+		file := jsonDiffFile{
+			Name:          f.Name,
+			Type:          f.Type.String(),
+			Index:         f.Index,
+			OldIndex:      f.OldIndex,
+			ParentIndexes: f.ParentIndexes,
+			Mode:          formatEntryMode(f.mode),
+			OldMode:       formatEntryMode(f.oldMode),
+			IsBinary:      f.isBinary,
+			IsSubmodule:   f.isSubmodule,
+			IsIncomplete:  f.isIncomplete,
+			IsCombined:    f.isCombined,
+			Additions:     f.numAdditions,
+			Deletions:     f.numDeletions,
+			Sections:      make([]jsonDiffSection, 0, len(f.Sections)),
+		}
+		if f.oldName != f.Name {
+			file.OldName = f.oldName
+		}
+		for _, s := range f.Sections {
+			section := jsonDiffSection{
+				Lines: make([]jsonDiffLine, 0, len(s.Lines)),
+			}
+			for _, l := range s.Lines {
+				line := jsonDiffLine{
+					Type:      l.Type.String(),
+					Content:   l.Content,
+					LeftLine:  l.LeftLine,
+					RightLine: l.RightLine,
+				}
+				for _, span := range l.Changes {
+					line.Changes = append(line.Changes, jsonDiffSpan(span))
+				}
+				if len(l.ParentTypes) > 0 {
+					line.ParentLines = l.ParentLines
+					line.ParentTypes = make([]string, len(l.ParentTypes))
+					for i, typ := range l.ParentTypes {
+						line.ParentTypes[i] = typ.String()
+					}
+				}
+				section.Lines = append(section.Lines, line)
+			}
+			file.Sections = append(file.Sections, section)
+		}
+		out.Files = append(out.Files, file)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", r.Indent)
+	return enc.Encode(out)
+}
+
+// HTMLDiffRenderer renders a diff as HTML tables, one for each file. All
+// contents are escaped, and intra-line changes are wrapped in
+// <span class="diff-change"> elements.
+//
+// The HTML uses the following classes for styling: "diff-file",
+// "diff-file-header", "diff-table", "diff-line" with one of "diff-plain",
+// "diff-add", "diff-delete", "diff-section" and "diff-empty", "diff-line-num",
+// "diff-line-code" and "diff-change".
+type HTMLDiffRenderer struct {
+	// Indicates whether to render the side-by-side view instead of the unified
+	// view.
+	SideBySide bool
+}
+
+// Render writes the diff to the writer as HTML.
+func (r *HTMLDiffRenderer) Render(w io.Writer, diff *Diff) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range diff.Files {
+		name := html.EscapeString(f.Name)
+		if f.oldName != "" && f.oldName != f.Name {
+			name = html.EscapeString(f.oldName) + " &rarr; " + name
+		}
+		_, _ = fmt.Fprintf(bw, `<div class="diff-file"><div class="diff-file-header">%s</div>`, name)
+		_, _ = bw.WriteString(`<table class="diff-table"><tbody>`)
+		for _, s := range f.Sections {
+			if r.SideBySide {
+				writeHTMLSideBySide(bw, s.SideBySide())
+			} else {
+				for _, l := range s.Lines {
+					writeHTMLUnifiedRow(bw, l)
+				}
+			}
+		}
+		_, _ = bw.WriteString("</tbody></table></div>\n")
+	}
+	return bw.Flush()
+}
+
+func htmlLineClass(l *DiffLine) string {
+	if l == nil {
+		return "diff-empty"
+	}
+	return "diff-" + l.Type.String()
+}
+
+func htmlLineNum(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// writeHTMLLineCode writes the escaped content of the line without the leading
+// marker, with intra-line changes highlighted. The class is added to the cell
+// when not empty.
+func writeHTMLLineCode(w *bufio.Writer, l *DiffLine, class string) {
+	if class == "" {
+		_, _ = w.WriteString(`<td class="diff-line-code">`)
+	} else {
+		_, _ = fmt.Fprintf(w, `<td class="diff-line-code %s">`, class)
+	}
+	if l != nil {
+		if l.Type == DiffLineSection {
+			_, _ = w.WriteString(html.EscapeString(l.Content))
+		} else if len(l.Content) >= l.markerLen() {
+			pos := l.markerLen()
+			for _, span := range l.Changes {
+				if span.Start < pos || span.End > len(l.Content) || span.End < span.Start {
+					continue
+				}
+				_, _ = w.WriteString(html.EscapeString(l.Content[pos:span.Start]))
+				_, _ = w.WriteString(`<span class="diff-change">`)
+				_, _ = w.WriteString(html.EscapeString(l.Content[span.Start:span.End]))
+				_, _ = w.WriteString(`</span>`)
+				pos = span.End
+			}
+			_, _ = w.WriteString(html.EscapeString(l.Content[pos:]))
+		}
+	}
+	_, _ = w.WriteString(`</td>`)
+}
+
+func writeHTMLUnifiedRow(w *bufio.Writer, l *DiffLine) {
+	_, _ = fmt.Fprintf(w, `<tr class="diff-line %s"><td class="diff-line-num">%s</td><td class="diff-line-num">%s</td>`,
+		htmlLineClass(l), htmlLineNum(l.LeftLine), htmlLineNum(l.RightLine))
+	writeHTMLLineCode(w, l, "")
+	_, _ = w.WriteString("</tr>\n")
+}
+
+func writeHTMLSideBySide(w *bufio.Writer, s *SideBySideSection) {
+	if s.Header != nil {
+		_, _ = fmt.Fprintf(w, `<tr class="diff-line diff-section"><td class="diff-line-num"></td><td class="diff-line-code" colspan="3">%s</td></tr>`+"\n",
+			html.EscapeString(s.Header.Content))
+	}
+	for _, row := range s.Rows {
+		_, _ = w.WriteString(`<tr class="diff-line">`)
+
+		var leftNum, rightNum int
+		if row.Left != nil {
+			leftNum = row.Left.LeftLine
+		}
+		if row.Right != nil {
+			rightNum = row.Right.RightLine
+		}
+		_, _ = fmt.Fprintf(w, `<td class="diff-line-num %s">%s</td>`, htmlLineClass(row.Left), htmlLineNum(leftNum))
+		writeHTMLLineCode(w, row.Left, htmlLineClass(row.Left))
+		_, _ = fmt.Fprintf(w, `<td class="diff-line-num %s">%s</td>`, htmlLineClass(row.Right), htmlLineNum(rightNum))
+		writeHTMLLineCode(w, row.Right, htmlLineClass(row.Right))
+		_, _ = w.WriteString("</tr>\n")
+	}
+}
@@ -0,0 +1,265 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRenderPatch = `diff --git a/README.md b/README.md
+index 0000001..0000002 100644
+--- a/README.md
++++ b/README.md
+@@ -1,3 +1,3 @@ Title
+ # Title
+-Hello <world>
++Hello, <Gopher>
+ End
+diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..0000003
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++new
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index 0000004..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-old
+diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+index 0000005..0000006
+--- a/run.sh
++++ b/run.sh
+@@ -1 +1 @@
+-echo hi
++echo hello
+diff --git a/a.go b/b.go
+similarity index 90%
+rename from a.go
+rename to b.go
+index 0000007..0000008 100644
+--- a/a.go
++++ b/b.go
+@@ -1 +1 @@
+-package a
++package b
+diff --git a/b.go b/c.go
+similarity index 100%
+copy from b.go
+copy to c.go
+diff --git a/img.png b/img.png
+index 0000009..000000a 100644
+Binary files a/img.png and b/img.png differ
+`
+
+func parseTestDiff(t *testing.T, patch string) *Diff {
+	done := make(chan SteamParseDiffResult)
+	go StreamParseDiff(strings.NewReader(patch), done, 0, 0, 0)
+	result := <-done
+	require.NoError(t, result.Err)
+	return result.Diff
+}
+
+func TestUnifiedDiffRenderer(t *testing.T) {
+	diff := parseTestDiff(t, testRenderPatch)
+
+	var buf bytes.Buffer
+	err := (&UnifiedDiffRenderer{}).Render(&buf, diff)
+	require.NoError(t, err)
+
+	assert.Equal(t, testRenderPatch, buf.String())
+
+	// Rendering the parsed output again must be stable
+	var again bytes.Buffer
+	err = (&UnifiedDiffRenderer{}).Render(&again, parseTestDiff(t, buf.String()))
+	require.NoError(t, err)
+	assert.Equal(t, buf.String(), again.String())
+}
+
+func TestUnifiedDiffRenderer_Apply(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip(`Windows does not allow '"' in filenames`)
+	}
+
+	path := tempPath()
+	defer os.RemoveAll(path)
+
+	err := Init(path)
+	require.NoError(t, err)
+	r, err := Open(path)
+	require.NoError(t, err)
+
+	sig := &Signature{Name: "alice", Email: "alice@example.com"}
+	commit := func(files map[string]string, remove ...string) {
+		for _, name := range remove {
+			require.NoError(t, os.Remove(filepath.Join(r.Path(), name)))
+		}
+		for name, content := range files {
+			require.NoError(t, os.WriteFile(filepath.Join(r.Path(), name), []byte(content), 0600))
+		}
+		require.NoError(t, r.Add(AddOptions{All: true}))
+		require.NoError(t, r.Commit(sig, "Update"))
+	}
+	commit(map[string]string{
+		"a b.txt":          "x",
+		"\u00e9 \"q\".txt": "y\n",
+	})
+	commit(map[string]string{
+		"a b.txt":       "x\nz",
+		"r \u00e9.txt":  "y\nw\n",
+		"tab\tname.txt": "t\n",
+	}, "\u00e9 \"q\".txt")
+
+	patch, err := NewCommand("diff", "--full-index", "-M", "HEAD~1", "HEAD").RunInDir(r.Path())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	err = (&UnifiedDiffRenderer{}).Render(&buf, parseTestDiff(t, string(patch)))
+	require.NoError(t, err)
+	assert.Equal(t, string(patch), buf.String())
+
+	// The rendered patch must be applicable in reverse to the current state
+	stderr := new(bytes.Buffer)
+	err = NewCommand("apply", "--check", "-R").RunInDirWithOptions(r.Path(), RunInDirOptions{
+		Stdin:  bytes.NewReader(buf.Bytes()),
+		Stdout: new(bytes.Buffer),
+		Stderr: stderr,
+	})
+	assert.NoError(t, err, stderr.String())
+}
+
+func TestUnifiedDiffRenderer_Combined(t *testing.T) {
+	patch := `diff --cc f.txt
+index b370228f70fc9d65467dbce365b207c7390c22d0,53eec95e02f369d6187bd0be18f7ca45b2b26496..f1886d7a9bebaeaa003091a293a851f366e30bd8
+--- a/f.txt
++++ b/f.txt
+@@@ -1,4 -1,3 +1,4 @@@
+  a
+- B master
+ -B side
+++B merged
+  c
+ +d
+diff --cc g.txt
+index 587be6b4c3f93f93c489c0111bba5596147a26cb,587be6b4c3f93f93c489c0111bba5596147a26cb..b68025345d5301abad4d9ec9166f455243a0d746
+mode 100644,100644..100755
+--- a/g.txt
++++ b/g.txt
+@@@ -1,1 -1,1 +1,1 @@@
+--x
+++z
+`
+	diff := parseTestDiff(t, patch)
+
+	var buf bytes.Buffer
+	err := (&UnifiedDiffRenderer{}).Render(&buf, diff)
+	require.NoError(t, err)
+	assert.Equal(t, patch, buf.String())
+}
+
+func TestDiffSection_SideBySide(t *testing.T) {
+	section := &DiffSection{
+		Lines: []*DiffLine{
+			{Type: DiffLineSection, Content: "@@ -1,5 +1,5 @@"},
+			{Type: DiffLinePlain, Content: " a", LeftLine: 1, RightLine: 1},
+			{Type: DiffLineDelete, Content: "-b", LeftLine: 2},
+			{Type: DiffLineDelete, Content: "-c", LeftLine: 3},
+			{Type: DiffLineAdd, Content: "+B", RightLine: 2},
+			{Type: DiffLinePlain, Content: " d", LeftLine: 4, RightLine: 3},
+			{Type: DiffLineAdd, Content: "+e", RightLine: 4},
+			{Type: DiffLineAdd, Content: "+f", RightLine: 5},
+		},
+	}
+	lines := section.Lines
+
+	view := section.SideBySide()
+	assert.Equal(t, lines[0], view.Header)
+	assert.Equal(t, []*SideBySideRow{
+		{Left: lines[1], Right: lines[1]},
+		{Left: lines[2], Right: lines[4]},
+		{Left: lines[3]},
+		{Left: lines[5], Right: lines[5]},
+		{Right: lines[6]},
+		{Right: lines[7]},
+	}, view.Rows)
+}
+
+func TestJSONDiffRenderer(t *testing.T) {
+	diff := parseTestDiff(t, `diff --git a/a.go b/b.go
+similarity index 90%
+rename from a.go
+rename to b.go
+index 0000007..0000008 100644
+--- a/a.go
++++ b/b.go
+@@ -1 +1 @@
+-package a
++package b
+`)
+	diff.ComputeInlineChanges(DiffInlineWord)
+
+	var buf bytes.Buffer
+	err := (&JSONDiffRenderer{}).Render(&buf, diff)
+	require.NoError(t, err)
+	assert.Equal(t, `{"files":[{"name":"b.go","old_name":"a.go","type":"rename","index":"0000008","old_index":"0000007","mode":"100644","old_mode":"100644","is_binary":false,"is_submodule":false,"is_incomplete":false,"additions":1,"deletions":1,"sections":[{"lines":[{"type":"section","content":"@@ -1 +1 @@"},{"type":"delete","content":"-package a","left_line":1,"changes":[{"start":9,"end":10}]},{"type":"add","content":"+package b","right_line":1,"changes":[{"start":9,"end":10}]}]}]}],"total_additions":1,"total_deletions":1,"is_incomplete":false}
+`, buf.String())
+
+	t.Run("empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := (&JSONDiffRenderer{Indent: "  "}).Render(&buf, new(Diff))
+		require.NoError(t, err)
+		assert.Equal(t, `{
+  "files": [],
+  "total_additions": 0,
+  "total_deletions": 0,
+  "is_incomplete": false
+}
+`, buf.String())
+	})
+}
+
+func TestHTMLDiffRenderer(t *testing.T) {
+	diff := parseTestDiff(t, testRenderPatch)
+	diff.Files = diff.Files[:1]
+	diff.ComputeInlineChanges(DiffInlineWord)
+
+	t.Run("unified", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := (&HTMLDiffRenderer{}).Render(&buf, diff)
+		require.NoError(t, err)
+		assert.Equal(t, `<div class="diff-file"><div class="diff-file-header">README.md</div><table class="diff-table"><tbody><tr class="diff-line diff-section"><td class="diff-line-num"></td><td class="diff-line-num"></td><td class="diff-line-code">@@ -1,3 +1,3 @@ Title</td></tr>
+<tr class="diff-line diff-plain"><td class="diff-line-num">1</td><td class="diff-line-num">1</td><td class="diff-line-code"># Title</td></tr>
+<tr class="diff-line diff-delete"><td class="diff-line-num">2</td><td class="diff-line-num"></td><td class="diff-line-code">Hello &lt;<span class="diff-change">world</span>&gt;</td></tr>
+<tr class="diff-line diff-add"><td class="diff-line-num"></td><td class="diff-line-num">2</td><td class="diff-line-code">Hello<span class="diff-change">,</span> &lt;<span class="diff-change">Gopher</span>&gt;</td></tr>
+<tr class="diff-line diff-plain"><td class="diff-line-num">3</td><td class="diff-line-num">3</td><td class="diff-line-code">End</td></tr>
+</tbody></table></div>
+`, buf.String())
+	})
+
+	t.Run("side-by-side", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := (&HTMLDiffRenderer{SideBySide: true}).Render(&buf, diff)
+		require.NoError(t, err)
+		assert.Equal(t, `<div class="diff-file"><div class="diff-file-header">README.md</div><table class="diff-table"><tbody><tr class="diff-line diff-section"><td class="diff-line-num"></td><td class="diff-line-code" colspan="3">@@ -1,3 +1,3 @@ Title</td></tr>
+<tr class="diff-line"><td class="diff-line-num diff-plain">1</td><td class="diff-line-code diff-plain"># Title</td><td class="diff-line-num diff-plain">1</td><td class="diff-line-code diff-plain"># Title</td></tr>
+<tr class="diff-line"><td class="diff-line-num diff-delete">2</td><td class="diff-line-code diff-delete">Hello &lt;<span class="diff-change">world</span>&gt;</td><td class="diff-line-num diff-add">2</td><td class="diff-line-code diff-add">Hello<span class="diff-change">,</span> &lt;<span class="diff-change">Gopher</span>&gt;</td></tr>
+<tr class="diff-line"><td class="diff-line-num diff-plain">3</td><td class="diff-line-code diff-plain">End</td><td class="diff-line-num diff-plain">3</td><td class="diff-line-code diff-plain">End</td></tr>
+</tbody></table></div>
+`, buf.String())
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDiffSection_NumLines(t *testing.T) {
@@ -422,6 +423,7 @@ rename to run.sh`,
 						numAdditions: 0,
 						numDeletions: 0,
 						oldName:      "runme.sh",
+						similarity:   100,
 						isBinary:     false,
 						isSubmodule:  false,
 						isIncomplete: false,
@@ -463,6 +465,7 @@ index b6fc4c620b67d95f953a5c1c1230aaab5db5a1b0..ab80bda5dd90d8b42be25ac2c7a071b7
 										Content:   `-hello`,
 										LeftLine:  1,
 										RightLine: 0,
+										noNewline: true,
 									}, {
 										Type:      DiffLineAdd,
 										Content:   `+hello`,
@@ -478,6 +481,7 @@ index b6fc4c620b67d95f953a5c1c1230aaab5db5a1b0..ab80bda5dd90d8b42be25ac2c7a071b7
 										Content:   `+fdsfdsfds`,
 										LeftLine:  0,
 										RightLine: 3,
+										noNewline: true,
 									},
 								},
 								numAdditions: 3,
@@ -578,6 +582,7 @@ index ce53c7e..56a156b 100644
 						numAdditions: 2,
 						numDeletions: 2,
 						oldName:      "src/app/tabs/teacher/teacher.module.ts",
+						similarity:   69,
 						mode:         0100644,
 						oldMode:      0100644,
 					},
@@ -844,3 +849,235 @@ new mode 100755`,
 		})
 	}
 }
+
+func TestStreamParseDiff_Combined(t *testing.T) {
+	input := `diff --cc f.txt
+index b370228f70fc9d65467dbce365b207c7390c22d0,53eec95e02f369d6187bd0be18f7ca45b2b26496..f1886d7a9bebaeaa003091a293a851f366e30bd8
+--- a/f.txt
++++ b/f.txt
+@@@ -1,4 -1,3 +1,4 @@@
+  a
+- B master
+ -B side
+++B merged
+  c
+ +d
+diff --cc g.txt
+index 587be6b4c3f93f93c489c0111bba5596147a26cb,587be6b4c3f93f93c489c0111bba5596147a26cb..b68025345d5301abad4d9ec9166f455243a0d746
+mode 100644,100644..100755
+--- a/g.txt
++++ b/g.txt
+@@@ -1,1 -1,1 +1,1 @@@
+--x
+++z
+diff --cc octopus.txt
+index 1111111,2222222,3333333..4444444
+--- a/octopus.txt
++++ b/octopus.txt
+@@@@ -1,2 -1,1 -1,1 +1,2 @@@@
+   same
+ ++both added
+`
+	done := make(chan SteamParseDiffResult)
+	go StreamParseDiff(strings.NewReader(input), done, 0, 0, 0)
+	result := <-done
+	require.NoError(t, result.Err)
+	diff := result.Diff
+
+	require.Len(t, diff.Files, 3)
+	assert.Equal(t, 4, diff.TotalAdditions())
+	assert.Equal(t, 3, diff.TotalDeletions())
+
+	f := diff.Files[0]
+	assert.Equal(t, "f.txt", f.Name)
+	assert.Equal(t, DiffFileChange, f.Type)
+	assert.True(t, f.IsCombined())
+	assert.Equal(t, 2, f.NumParents())
+	assert.Equal(t, []string{"b370228f70fc9d65467dbce365b207c7390c22d0", "53eec95e02f369d6187bd0be18f7ca45b2b26496"}, f.ParentIndexes)
+	assert.Equal(t, "b370228f70fc9d65467dbce365b207c7390c22d0", f.OldIndex)
+	assert.Equal(t, "f1886d7a9bebaeaa003091a293a851f366e30bd8", f.Index)
+	assert.Nil(t, f.ParentModes())
+	require.Len(t, f.Sections, 1)
+	assert.Equal(t, []*DiffLine{
+		{Type: DiffLineSection, Content: "@@@ -1,4 -1,3 +1,4 @@@"},
+		{
+			Type: DiffLinePlain, Content: "  a", LeftLine: 1, RightLine: 1,
+			ParentLines: []int{1, 1}, ParentTypes: []DiffLineType{DiffLinePlain, DiffLinePlain},
+		},
+		{
+			Type: DiffLineDelete, Content: "- B master", LeftLine: 2,
+			ParentLines: []int{2, 0}, ParentTypes: []DiffLineType{DiffLineDelete, DiffLinePlain},
+		},
+		{
+			Type: DiffLineDelete, Content: " -B side",
+			ParentLines: []int{0, 2}, ParentTypes: []DiffLineType{DiffLinePlain, DiffLineDelete},
+		},
+		{
+			Type: DiffLineAdd, Content: "++B merged", RightLine: 2,
+			ParentLines: []int{0, 0}, ParentTypes: []DiffLineType{DiffLineAdd, DiffLineAdd},
+		},
+		{
+			Type: DiffLinePlain, Content: "  c", LeftLine: 3, RightLine: 3,
+			ParentLines: []int{3, 3}, ParentTypes: []DiffLineType{DiffLinePlain, DiffLinePlain},
+		},
+		{
+			Type: DiffLineAdd, Content: " +d", LeftLine: 4, RightLine: 4,
+			ParentLines: []int{4, 0}, ParentTypes: []DiffLineType{DiffLinePlain, DiffLineAdd},
+		},
+	}, f.Sections[0].Lines)
+
+	f = diff.Files[1]
+	assert.Equal(t, "g.txt", f.Name)
+	assert.Equal(t, EntryExec, f.Mode())
+	assert.Equal(t, EntryBlob, f.OldMode())
+	assert.Equal(t, []EntryMode{EntryBlob, EntryBlob}, f.ParentModes())
+	assert.Equal(t, 1, f.NumAdditions())
+	assert.Equal(t, 1, f.NumDeletions())
+
+	f = diff.Files[2]
+	assert.Equal(t, 3, f.NumParents())
+	require.Len(t, f.Sections, 1)
+	lines := f.Sections[0].Lines
+	require.Len(t, lines, 3)
+	assert.Equal(t, []int{1, 1, 1}, lines[1].ParentLines)
+	assert.Equal(t, 2, lines[2].RightLine)
+	assert.Equal(t, []int{2, 0, 0}, lines[2].ParentLines)
+	assert.Equal(t, []DiffLineType{DiffLinePlain, DiffLineAdd, DiffLineAdd}, lines[2].ParentTypes)
+
+	t.Run("new and deleted files", func(t *testing.T) {
+		input := `diff --cc added.txt
+index 0000000,0000000..e69de29
+new file mode 100644
+diff --cc removed.txt
+index e69de29,e69de29..0000000
+deleted file mode 100644,100644
+`
+		done := make(chan SteamParseDiffResult)
+		go StreamParseDiff(strings.NewReader(input), done, 0, 0, 0)
+		result := <-done
+		require.NoError(t, result.Err)
+		require.Len(t, result.Diff.Files, 2)
+		assert.Equal(t, DiffFileAdd, result.Diff.Files[0].Type)
+		assert.Equal(t, EntryBlob, result.Diff.Files[0].Mode())
+		assert.Equal(t, DiffFileDelete, result.Diff.Files[1].Type)
+		assert.Equal(t, []EntryMode{EntryBlob, EntryBlob}, result.Diff.Files[1].ParentModes())
+	})
+
+	t.Run("quoted names", func(t *testing.T) {
+		input := `diff --cc "caf\303\251.txt"
+index 0000000,0000000..e69de29
+new file mode 100644
+diff --cc "tab\tname.txt"
+index e69de29,e69de29..0000000
+deleted file mode 100644,100644
+`
+		done := make(chan SteamParseDiffResult)
+		go StreamParseDiff(strings.NewReader(input), done, 0, 0, 0)
+		result := <-done
+		require.NoError(t, result.Err)
+		require.Len(t, result.Diff.Files, 2)
+		assert.Equal(t, "café.txt", result.Diff.Files[0].Name)
+		assert.Equal(t, "tab\tname.txt", result.Diff.Files[1].Name)
+	})
+
+	t.Run("malformed index", func(t *testing.T) {
+		for _, input := range []string{
+			"diff --cc f.txt\nindex \n",
+			"diff --cc f.txt\nindex 1111111,2222222\n",
+		} {
+			done := make(chan SteamParseDiffResult)
+			go StreamParseDiff(strings.NewReader(input), done, 0, 0, 0)
+			result := <-done
+			assert.EqualError(t, result.Err, "malformed index: expect SHAs in the form of <parent>,<parent>..<new>")
+		}
+	})
+}
+
+func TestStreamParseDiff_Copy(t *testing.T) {
+	input := `diff --git a/a.txt b/b.txt
+similarity index 100%
+copy from a.txt
+copy to b.txt
+diff --git a/a.txt b/c.txt
+similarity index 75%
+copy from a.txt
+copy to c.txt
+index 4fcc7ae0d2ab7c4ac1d2d4bb9b01a0b43b2f1f1c..e8e5bd3ff0e0cbe2d01b4f87e9ff0f12d4ee51e9 100644
+--- a/a.txt
++++ b/c.txt
+@@ -1,4 +1,4 @@
+ 1
+ 2
+ 3
+-4
++four
+diff --git a/d.txt b/e.txt
+similarity index 100%
+rename from d.txt
+rename to e.txt
+`
+	done := make(chan SteamParseDiffResult)
+	go StreamParseDiff(strings.NewReader(input), done, 0, 0, 0)
+	result := <-done
+	require.NoError(t, result.Err)
+	require.Len(t, result.Diff.Files, 3)
+
+	f := result.Diff.Files[0]
+	assert.Equal(t, DiffFileCopy, f.Type)
+	assert.True(t, f.IsCopied())
+	assert.False(t, f.IsRenamed())
+	assert.Equal(t, "b.txt", f.Name)
+	assert.Equal(t, "a.txt", f.OldName())
+	assert.Empty(t, f.Sections)
+
+	f = result.Diff.Files[1]
+	assert.Equal(t, DiffFileCopy, f.Type)
+	assert.Equal(t, "c.txt", f.Name)
+	assert.Equal(t, "a.txt", f.OldName())
+	assert.Equal(t, "e8e5bd3ff0e0cbe2d01b4f87e9ff0f12d4ee51e9", f.Index)
+	assert.Equal(t, 1, f.NumAdditions())
+	assert.Equal(t, 1, f.NumDeletions())
+
+	f = result.Diff.Files[2]
+	assert.Equal(t, DiffFileRename, f.Type)
+	assert.Equal(t, "e.txt", f.Name)
+	assert.Equal(t, "d.txt", f.OldName())
+}
+
+func TestDiffSection_hunkRange(t *testing.T) {
+	tests := []struct {
+		header        string
+		expLeftFirst  int
+		expLeftCount  int
+		expRightFirst int
+		expRightCount int
+		expSuffix     string
+	}{
+		{header: "@@ -1,3 +1,4 @@", expLeftFirst: 1, expLeftCount: 3, expRightFirst: 1, expRightCount: 4},
+		{header: "@@ -5 +5 @@ func main() {", expLeftFirst: 5, expLeftCount: 1, expRightFirst: 5, expRightCount: 1, expSuffix: " func main() {"},
+		{header: "@@ -0,0 +1,2 @@", expLeftFirst: 1, expLeftCount: 0, expRightFirst: 1, expRightCount: 2},
+		{header: "@@ -7,2 +6,0 @@", expLeftFirst: 7, expLeftCount: 2, expRightFirst: 7, expRightCount: 0},
+	}
+	for _, test := range tests {
+		t.Run(test.header, func(t *testing.T) {
+			section := &DiffSection{Lines: []*DiffLine{{Type: DiffLineSection, Content: test.header}}}
+			leftFirst, leftCount, rightFirst, rightCount, suffix, err := section.hunkRange()
+			require.NoError(t, err)
+			assert.Equal(t, test.expLeftFirst, leftFirst)
+			assert.Equal(t, test.expLeftCount, leftCount)
+			assert.Equal(t, test.expRightFirst, rightFirst)
+			assert.Equal(t, test.expRightCount, rightCount)
+			assert.Equal(t, test.expSuffix, suffix)
+
+			assert.Equal(t, test.header, formatHunkHeader(leftFirst, leftCount, rightFirst, rightCount, suffix))
+		})
+	}
+
+	t.Run("malformed", func(t *testing.T) {
+		for _, header := range []string{"", "@@ -1,3 @@", "@@ -a +1 @@", "@@@ -1 -1 +1 @@@"} {
+			section := &DiffSection{Lines: []*DiffLine{{Type: DiffLineSection, Content: header}}}
+			_, _, _, _, _, err := section.hunkRange()
+			assert.Error(t, err, header)
+		}
+	})
+}
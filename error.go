@@ -18,4 +18,7 @@ var (
 	ErrNoMergeBase          = errors.New("no merge based was found")
 	ErrNotBlob              = errors.New("the entry is not a blob")
 	ErrNotDeleteNonPushURLs = errors.New("will not delete all non-push URLs")
+	ErrNotSigned            = errors.New("the object is not signed")
+	ErrDiffFileNotExist     = errors.New("file does not exist in the diff")
+	ErrPatchRejected        = errors.New("the patch does not apply")
 )
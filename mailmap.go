@@ -0,0 +1,156 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// mailmapIdentity is the canonical identity to replace with. An empty field
+// means no replacement for that field.
+type mailmapIdentity struct {
+	name  string
+	email string
+}
+
+// mailmapEntry contains the mappings of an email as it appears in commits.
+type mailmapEntry struct {
+	// The mapping when only the email is matched.
+	mailmapIdentity
+	// The mappings when both the name and the email are matched, keyed by the
+	// lower-cased name as it appears in commits.
+	names map[string]*mailmapIdentity
+}
+
+// Mailmap contains the mappings of author and committer names and emails to
+// their canonical identities.
+//
+// Docs: https://git-scm.com/docs/gitmailmap
+type Mailmap struct {
+	// Entries keyed by the lower-cased email as it appears in commits.
+	entries map[string]*mailmapEntry
+}
+
+// NewMailmap returns a new Mailmap with no mappings.
+func NewMailmap() *Mailmap {
+	return &Mailmap{
+		entries: make(map[string]*mailmapEntry),
+	}
+}
+
+// ParseMailmap parses the content of a ".mailmap" file.
+func ParseMailmap(data []byte) *Mailmap {
+	m := NewMailmap()
+	m.Parse(data)
+	return m
+}
+
+// parseMailmapIdentity parses a name and an email in the form of "Name <email>"
+// from the start of given line, and returns the rest of the line. The name may
+// be empty. It returns false if no email is found.
+func parseMailmapIdentity(line string) (name, email, rest string, ok bool) {
+	start := strings.IndexByte(line, '<')
+	if start < 0 {
+		return "", "", line, false
+	}
+	end := strings.IndexByte(line[start+1:], '>')
+	if end < 0 {
+		return "", "", line, false
+	}
+	end += start + 1
+
+	name = strings.TrimSpace(line[:start])
+	email = line[start+1 : end]
+	return name, email, line[end+1:], true
+}
+
+// Parse parses the content of a ".mailmap" file and adds its mappings to the
+// Mailmap. Mappings parsed later take precedence over the existing ones.
+func (m *Mailmap) Parse(data []byte) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Anything after the last email is ignored, thus only need to skip comment
+		// lines.
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		newName, newEmail, rest, ok := parseMailmapIdentity(line)
+		if !ok {
+			continue
+		}
+		oldName, oldEmail, _, ok := parseMailmapIdentity(rest)
+		if !ok {
+			// "Proper Name <commit@email.xx>" only replaces the name.
+			oldEmail = newEmail
+			newEmail = ""
+		}
+		m.add(newName, newEmail, oldName, oldEmail)
+	}
+}
+
+func (m *Mailmap) add(newName, newEmail, oldName, oldEmail string) {
+	key := strings.ToLower(oldEmail)
+	entry, ok := m.entries[key]
+	if !ok {
+		entry = &mailmapEntry{
+			names: make(map[string]*mailmapIdentity),
+		}
+		m.entries[key] = entry
+	}
+
+	if oldName == "" {
+		if newName != "" {
+			entry.name = newName
+		}
+		if newEmail != "" {
+			entry.email = newEmail
+		}
+		return
+	}
+
+	entry.names[strings.ToLower(oldName)] = &mailmapIdentity{
+		name:  newName,
+		email: newEmail,
+	}
+}
+
+// Resolve returns the signature with canonical name and email according to
+// the mappings. The time of the signature is preserved. It returns the given
+// signature as-is when there is no matching mapping.
+func (m *Mailmap) Resolve(sig *Signature) *Signature {
+	if m == nil || sig == nil {
+		return sig
+	}
+
+	entry, ok := m.entries[strings.ToLower(sig.Email)]
+	if !ok {
+		return sig
+	}
+
+	identity := &entry.mailmapIdentity
+	if named, ok := entry.names[strings.ToLower(sig.Name)]; ok {
+		identity = named
+	}
+	if identity.name == "" && identity.email == "" {
+		return sig
+	}
+
+	resolved := &Signature{
+		Name:  sig.Name,
+		Email: sig.Email,
+		When:  sig.When,
+	}
+	if identity.name != "" {
+		resolved.Name = identity.name
+	}
+	if identity.email != "" {
+		resolved.Email = identity.email
+	}
+	return resolved
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMailmap_Resolve(t *testing.T) {
+	m := ParseMailmap([]byte(`# A comment line
+Joe Developer <joe@example.com>
+<jane@example.com> <jane@laptop.(none)>
+Jane Doe <jane@example.com> jane <JANE@desktop.(none)>
+Other Author <other@author.xx> nick1 <bugs@company.xx> # trailing comment
+Santa Claus <santa.claus@northpole.xx> <me@company.xx>
+`))
+	// Mappings parsed later take precedence
+	m.Parse([]byte(`Joe R. Developer <joe@example.com>`))
+
+	when := time.Unix(1378823654, 0)
+	tests := []struct {
+		name string
+		sig  *Signature
+		want *Signature
+	}{
+		{
+			name: "replace name only",
+			sig:  &Signature{Name: "joe", Email: "Joe@Example.com", When: when},
+			want: &Signature{Name: "Joe R. Developer", Email: "Joe@Example.com", When: when},
+		},
+		{
+			name: "replace email only",
+			sig:  &Signature{Name: "Jane", Email: "jane@laptop.(none)", When: when},
+			want: &Signature{Name: "Jane", Email: "jane@example.com", When: when},
+		},
+		{
+			name: "replace by name and email",
+			sig:  &Signature{Name: "Jane", Email: "jane@desktop.(none)", When: when},
+			want: &Signature{Name: "Jane Doe", Email: "jane@example.com", When: when},
+		},
+		{
+			name: "name does not match",
+			sig:  &Signature{Name: "nick2", Email: "bugs@company.xx", When: when},
+			want: &Signature{Name: "nick2", Email: "bugs@company.xx", When: when},
+		},
+		{
+			name: "replace both",
+			sig:  &Signature{Name: "Santa", Email: "me@company.xx", When: when},
+			want: &Signature{Name: "Santa Claus", Email: "santa.claus@northpole.xx", When: when},
+		},
+		{
+			name: "no mapping",
+			sig:  &Signature{Name: "alice", Email: "alice@example.com", When: when},
+			want: &Signature{Name: "alice", Email: "alice@example.com", When: when},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, m.Resolve(test.sig))
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		var m *Mailmap
+		sig := &Signature{Name: "alice", Email: "alice@example.com"}
+		assert.Equal(t, sig, m.Resolve(sig))
+		assert.Nil(t, NewMailmap().Resolve(nil))
+	})
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"strings"
+)
+
+// SignatureFormat is the format of a cryptographic signature, which has the
+// same values as the "gpg.format" config.
+type SignatureFormat string
+
+// A list of signature formats.
+const (
+	SignatureFormatOpenPGP SignatureFormat = "openpgp"
+	SignatureFormatX509    SignatureFormat = "x509"
+	SignatureFormatSSH     SignatureFormat = "ssh"
+)
+
+// signatureHeaders maps the first line of an armored signature to its format.
+var signatureHeaders = []struct {
+	header string
+	format SignatureFormat
+}{
+	{"-----BEGIN PGP SIGNATURE-----", SignatureFormatOpenPGP},
+	{"-----BEGIN PGP MESSAGE-----", SignatureFormatOpenPGP},
+	{"-----BEGIN SIGNED MESSAGE-----", SignatureFormatX509},
+	{"-----BEGIN SSH SIGNATURE-----", SignatureFormatSSH},
+}
+
+// ObjectSignature contains the cryptographic signature of a commit or tag
+// object along with the payload that it signs.
+type ObjectSignature struct {
+	// The armored signature.
+	Signature string
+	// The signed payload, which is the raw object without the signature.
+	Payload string
+}
+
+// Format returns the format of the signature. It returns an empty string if the
+// format is unknown.
+func (s *ObjectSignature) Format() SignatureFormat {
+	for _, h := range signatureHeaders {
+		if strings.HasPrefix(s.Signature, h.header) {
+			return h.format
+		}
+	}
+	return ""
+}
+
+// trailingSignatureStart returns the start position of the signature that is
+// appended to given data (e.g. the message of a signed tag). It returns -1 if
+// there is no such signature.
+func trailingSignatureStart(data []byte) int {
+	// The signature starts at the beginning of a line, and the last one wins.
+	start := -1
+	for _, h := range signatureHeaders {
+		i := bytes.LastIndex(data, []byte(h.header))
+		if i < 0 || (i > 0 && data[i-1] != '\n') {
+			continue
+		}
+		if i > start {
+			start = i
+		}
+	}
+	return start
+}
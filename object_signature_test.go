@@ -0,0 +1,46 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectSignature_Format(t *testing.T) {
+	tests := []struct {
+		signature string
+		want      SignatureFormat
+	}{
+		{signature: "-----BEGIN PGP SIGNATURE-----\n\n...\n-----END PGP SIGNATURE-----\n", want: SignatureFormatOpenPGP},
+		{signature: "-----BEGIN SIGNED MESSAGE-----\n...\n-----END SIGNED MESSAGE-----\n", want: SignatureFormatX509},
+		{signature: "-----BEGIN SSH SIGNATURE-----\n...\n-----END SSH SIGNATURE-----\n", want: SignatureFormatSSH},
+		{signature: "something else", want: ""},
+	}
+	for _, test := range tests {
+		t.Run(string(test.want), func(t *testing.T) {
+			s := &ObjectSignature{Signature: test.signature}
+			assert.Equal(t, test.want, s.Format())
+		})
+	}
+}
+
+func Test_trailingSignatureStart(t *testing.T) {
+	tests := []struct {
+		data string
+		want int
+	}{
+		{data: "The version 1.1.0\n", want: -1},
+		{data: "The version 1.1.0\n-----BEGIN PGP SIGNATURE-----\n...", want: 18},
+		{data: "-----BEGIN SSH SIGNATURE-----\n...", want: 0},
+		{data: "Not at start of line -----BEGIN PGP SIGNATURE-----\n...", want: -1},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, test.want, trailingSignatureStart([]byte(test.data)))
+		})
+	}
+}
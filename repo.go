@@ -7,7 +7,6 @@ package git
 import (
 	"bufio"
 	"bytes"
-	"fmt"
 	"io"
 	"os"
 	"path"
@@ -450,7 +449,13 @@ func (r *Repository) Add(opts ...AddOptions) error {
 // Docs: https://git-scm.com/docs/git-commit
 type CommitOptions struct {
 	// Author is the author of the changes if that's not the same as committer.
+	// The time of the author, when not zero, is used as the author date.
 	Author *Signature
+	// The signer to sign the commit with. The commit is not signed when not set.
+	// Signers that are not supported by Git natively (i.e. other than GPGSigner
+	// and SSHSigner) sign after the commit is created, and the branch is then
+	// updated to the signed commit.
+	Signer Signer
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -461,20 +466,25 @@ type CommitOptions struct {
 }
 
 // CreateCommit commits local changes with given author, committer and message
-// for the repository in given path.
+// for the repository in given path. The time of the committer, when not zero,
+// is used as the commit date, and also as the author date if the author is not
+// set in options.
 func CreateCommit(repoPath string, committer *Signature, message string, opts ...CommitOptions) error {
 	var opt CommitOptions
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
 
-	cmd := NewCommand("commit")
+	cmd, signing := signingCommand(opt.Signer, "commit")
 	cmd.AddCommitter(committer)
+	if signing {
+		cmd.AddArgs("-S")
+	}
 
 	if opt.Author == nil {
 		opt.Author = committer
 	}
-	cmd = cmd.AddArgs(fmt.Sprintf("--author='%s <%s>'", opt.Author.Name, opt.Author.Email)).
+	cmd = cmd.AddEnvs(signatureEnvs("AUTHOR", opt.Author)...).
 		AddArgs("-m", message).
 		AddOptions(opt.CommandOptions)
 
@@ -482,7 +492,21 @@ func CreateCommit(repoPath string, committer *Signature, message string, opts ..
 	// No stderr but exit status 1 means nothing to commit.
 	if err != nil && err.Error() == "exit status 1" {
 		return nil
+	} else if err != nil || opt.Signer == nil || signing {
+		return err
+	}
+
+	// Sign the new commit and move the branch to the signed one.
+	stdout, err := NewCommand("rev-parse", "--verify", "HEAD").RunInDirWithTimeout(opt.Timeout, repoPath)
+	if err != nil {
+		return err
+	}
+	unsigned := strings.TrimSpace(string(stdout))
+	signed, err := signObject(repoPath, "commit", unsigned, opt.Signer, opt.Timeout)
+	if err != nil {
+		return err
 	}
+	_, err = NewCommand("update-ref", "-m", "commit: sign", "HEAD", signed.String(), unsigned).RunInDirWithTimeout(opt.Timeout, repoPath)
 	return err
 }
 
@@ -0,0 +1,156 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// activityLogFormat is the log format to be used for activity statistics. Each
+// commit starts with a NUL, followed by the author name, email and date which
+// are mapped by the ".mailmap".
+const activityLogFormat = "tformat:%x00%aN%x00%aE%x00%aI"
+
+// parseActivityStats parses the output of "git log --numstat" in the format of
+// activityLogFormat in a single streaming pass.
+func parseActivityStats(r io.Reader) (*ActivityStats, error) {
+	stats := &ActivityStats{
+		Authors: []*AuthorActivity{},
+		Weeks:   []*ActivityWeek{},
+	}
+
+	var (
+		hasCommit   bool
+		name, email string
+		when        time.Time
+		additions   int
+		deletions   int
+	)
+	flush := func() {
+		if hasCommit {
+			stats.addCommit(name, email, when, additions, deletions)
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		// A new commit, e.g. \x00Alice\x00alice@example.com\x002020-02-09T20:18:00+08:00
+		if line[0] == 0 {
+			flush()
+
+			fields := bytes.Split(line[1:], []byte{0})
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("malformed commit line: %q", line)
+			}
+
+			var err error
+			when, err = time.Parse(time.RFC3339, string(fields[2]))
+			if err != nil {
+				return nil, fmt.Errorf("parse author date: %v", err)
+			}
+			hasCommit = true
+			name = string(fields[0])
+			email = string(fields[1])
+			additions = 0
+			deletions = 0
+			continue
+		}
+
+		// Line statistics of a file, e.g. 1\t2\tREADME.txt, binary files have "-"
+		// for both numbers.
+		fields := strings.SplitN(string(line), "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if n, err := strconv.Atoi(fields[0]); err == nil {
+			additions += n
+		}
+		if n, err := strconv.Atoi(fields[1]); err == nil {
+			deletions += n
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	stats.sort()
+	return stats, nil
+}
+
+// ActivityStatsOptions contains optional arguments for computing activity
+// statistics.
+//
+// Docs: https://git-scm.com/docs/git-log#Documentation/git-log.txt---numstat
+type ActivityStatsOptions struct {
+	// Indicates whether to exclude merge commits.
+	NoMerges bool
+	// The relative path of the repository.
+	Path string
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// ActivityStats returns the contributor and activity statistics of commits in
+// the state of given revision, that are committed within the time range of
+// since and until. Zero values of since or until mean no limit on that side.
+// Authors are normalized through the ".mailmap" of the repository.
+func (r *Repository) ActivityStats(rev string, since, until time.Time, opts ...ActivityStatsOptions) (*ActivityStats, error) {
+	var opt ActivityStatsOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("log").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--numstat", "--pretty="+activityLogFormat)
+	if !since.IsZero() {
+		cmd.AddArgs("--since=" + since.Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		cmd.AddArgs("--until=" + until.Format(time.RFC3339))
+	}
+	if opt.NoMerges {
+		cmd.AddArgs("--no-merges")
+	}
+	cmd.AddArgs("--end-of-options", rev, "--")
+	if opt.Path != "" {
+		cmd.AddArgs(escapePath(opt.Path))
+	}
+
+	type result struct {
+		stats *ActivityStats
+		err   error
+	}
+	stdout, w := io.Pipe()
+	done := make(chan result, 1)
+	go func() {
+		stats, err := parseActivityStats(stdout)
+		// Drain the rest of output to not block the command in case of error
+		_, _ = io.Copy(io.Discard, stdout)
+		done <- result{stats: stats, err: err}
+	}()
+
+	stderr := new(bytes.Buffer)
+	err := cmd.RunInDirPipeline(w, stderr, r.path)
+	_ = w.Close() // Close writer to exit parsing goroutine
+	res := <-done
+	if err != nil {
+		return nil, concatenateError(err, stderr.String())
+	}
+	return res.stats, res.err
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseActivityStats(t *testing.T) {
+	input := strings.Join([]string{
+		"\x00Alice\x00alice@example.com\x002020-02-09T20:18:00+08:00",
+		"",
+		"1\t2\tREADME.txt",
+		"-\t-\timage.png",
+		"\x00Bob\x00bob@example.com\x002020-02-03T09:00:00-05:00",
+		"\x00Alice\x00alice@example.com\x002020-02-01T09:00:00Z",
+		"",
+		"3\t0\tsrc/{a.go => b.go}",
+		"",
+	}, "\n")
+
+	stats, err := parseActivityStats(strings.NewReader(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, stats.TotalCommits)
+	require.Len(t, stats.Authors, 2)
+	assert.Equal(t, "Alice", stats.Authors[0].Name)
+	assert.Equal(t, 2, stats.Authors[0].Commits)
+	assert.Equal(t, 4, stats.Authors[0].Additions)
+	assert.Equal(t, 2, stats.Authors[0].Deletions)
+	assert.Equal(t, "Bob", stats.Authors[1].Name)
+	assert.Equal(t, 1, stats.Authors[1].Commits)
+
+	// Punch card uses the author's local time zone
+	assert.Equal(t, 1, stats.PunchCard[time.Sunday][20])
+	assert.Equal(t, 1, stats.PunchCard[time.Monday][9])
+	assert.Equal(t, 1, stats.PunchCard[time.Saturday][9])
+
+	want := []*ActivityWeek{
+		{
+			Week:      time.Date(2020, 1, 26, 0, 0, 0, 0, time.UTC),
+			Commits:   1,
+			Additions: 3,
+		},
+		{
+			Week:    time.Date(2020, 2, 2, 0, 0, 0, 0, time.UTC),
+			Commits: 1,
+		},
+		{
+			Week:      time.Date(2020, 2, 9, 0, 0, 0, 0, time.UTC),
+			Commits:   1,
+			Additions: 1,
+			Deletions: 2,
+		},
+	}
+	assert.Equal(t, want, stats.Weeks)
+
+	t.Run("malformed commit line", func(t *testing.T) {
+		_, err := parseActivityStats(strings.NewReader("\x00Alice\x00alice@example.com"))
+		assert.Error(t, err)
+	})
+
+	t.Run("no commits", func(t *testing.T) {
+		stats, err := parseActivityStats(strings.NewReader(""))
+		require.NoError(t, err)
+		assert.Zero(t, stats.TotalCommits)
+		assert.Empty(t, stats.Authors)
+		assert.Empty(t, stats.Weeks)
+	})
+}
+
+func TestRepository_ActivityStats(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	// Commit twice with different emails of the same person, which are normalized
+	// through the ".mailmap".
+	mailmap := "Alice <alice@example.com> <alice@old.example.com>\n"
+	err = os.WriteFile(filepath.Join(r.Path(), ".mailmap"), []byte(mailmap), 0600)
+	require.NoError(t, err)
+	require.NoError(t, r.Add(AddOptions{All: true}))
+	require.NoError(t, r.Commit(&Signature{Name: "alice", Email: "alice@example.com"}, "Add mailmap"))
+
+	err = os.WriteFile(filepath.Join(r.Path(), "activity.txt"), []byte("1\n2\n"), 0600)
+	require.NoError(t, err)
+	require.NoError(t, r.Add(AddOptions{All: true}))
+	require.NoError(t, r.Commit(&Signature{Name: "alice", Email: "alice@old.example.com"}, "Add activity"))
+
+	stats, err := r.ActivityStats("master~2..master", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, stats.TotalCommits)
+	require.Len(t, stats.Authors, 1)
+	assert.Equal(t, "Alice", stats.Authors[0].Name)
+	assert.Equal(t, "alice@example.com", stats.Authors[0].Email)
+	assert.Equal(t, 2, stats.Authors[0].Commits)
+	assert.Equal(t, 3, stats.Authors[0].Additions)
+
+	t.Run("limit to path", func(t *testing.T) {
+		stats, err := r.ActivityStats("master~2..master", time.Time{}, time.Time{}, ActivityStatsOptions{
+			Path: "activity.txt",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 1, stats.TotalCommits)
+		assert.Equal(t, 2, stats.Weeks[0].Additions)
+	})
+
+	t.Run("time range", func(t *testing.T) {
+		stats, err := r.ActivityStats("master", time.Now().AddDate(1, 0, 0), time.Time{})
+		require.NoError(t, err)
+		assert.Zero(t, stats.TotalCommits)
+	})
+
+	t.Run("bad revision", func(t *testing.T) {
+		_, err := r.ActivityStats("404", time.Time{}, time.Time{})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,248 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ApplyPatchOptions contains optional arguments for applying a patch.
+//
+// Docs: https://git-scm.com/docs/git-apply
+type ApplyPatchOptions struct {
+	// Indicates whether to only check if the patch applies without applying it.
+	Check bool
+	// Indicates whether to fall back on three-way merge when the patch does not
+	// apply cleanly. Conflicts are left in the index and the working tree.
+	ThreeWay bool
+	// Indicates whether to apply the patch to the index only without touching
+	// the working tree.
+	Cached bool
+	// The path of the index file to be used instead of the default one. Together
+	// with Cached, it allows applying patches in bare repositories.
+	IndexFile string
+	// Indicates whether to apply the patch in reverse.
+	Reverse bool
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// PatchRejection is a part of a patch that fails to apply.
+type PatchRejection struct {
+	// The path of the file.
+	Path string
+	// The 1-based index of the hunk in the file. It is 0 when the file is
+	// rejected as a whole, e.g. the file to be created already exists.
+	Hunk int
+	// The line number where the hunk is expected to apply. It is 0 when the file
+	// is rejected as a whole.
+	Line int
+	// The reason of the rejection reported by Git.
+	Reason string
+}
+
+// ApplyPatchResult contains the result of applying a patch.
+type ApplyPatchResult struct {
+	// The parts of the patch that fail to apply. Nothing is applied when it is
+	// not empty.
+	Rejections []*PatchRejection
+	// The paths of files that are applied with conflicts, only set with
+	// ThreeWay.
+	Conflicts []string
+}
+
+// parseApplyOutput parses the stderr of "git apply" for rejections and
+// conflicts, e.g.
+//
+//	error: patch failed: README.txt:12
+//	error: README.txt: patch does not apply
+//	error: main.go: already exists in working directory
+//	Applied patch to 'a.txt' with conflicts.
+func parseApplyOutput(data []byte) (rejections []*PatchRejection, conflicts []string) {
+	const (
+		prefixError       = "error: "
+		prefixPatchFailed = "error: patch failed: "
+		prefixSearching   = "error: while searching for:"
+		suffixNotApply    = ": patch does not apply"
+	)
+
+	rejected := make(map[string]bool)
+	searching := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// The verbose output dumps the lines of the failed hunk before the failure,
+		// which could be anything.
+		if searching && !strings.HasPrefix(line, prefixPatchFailed) {
+			continue
+		}
+		searching = false
+
+		switch {
+		case strings.HasPrefix(line, prefixSearching):
+			searching = true
+
+		case strings.HasPrefix(line, prefixPatchFailed):
+			target := line[len(prefixPatchFailed):]
+			i := strings.LastIndexByte(target, ':')
+			if i <= 0 {
+				continue
+			}
+			lineNum, err := strconv.Atoi(target[i+1:])
+			if err != nil {
+				continue
+			}
+			rejections = append(rejections, &PatchRejection{
+				Path:   target[:i],
+				Line:   lineNum,
+				Reason: "patch failed",
+			})
+			rejected[target[:i]] = true
+
+		case strings.HasPrefix(line, prefixError):
+			target := line[len(prefixError):]
+			// A summary of failed hunks of the file, only kept when no hunk is
+			// reported.
+			if strings.HasSuffix(target, suffixNotApply) && rejected[strings.TrimSuffix(target, suffixNotApply)] {
+				continue
+			}
+
+			i := strings.LastIndex(target, ": ")
+			if i <= 0 {
+				continue
+			}
+			rejections = append(rejections, &PatchRejection{
+				Path:   target[:i],
+				Reason: target[i+2:],
+			})
+			rejected[target[:i]] = true
+
+		case strings.HasPrefix(line, "Applied patch to '") && strings.HasSuffix(line, "' with conflicts."):
+			conflicts = append(conflicts, line[len("Applied patch to '"):len(line)-len("' with conflicts.")])
+		}
+	}
+	return rejections, conflicts
+}
+
+// setRejectedHunks sets the index of hunks of rejections by matching their
+// line numbers with sections of the patch.
+func setRejectedHunks(rejections []*PatchRejection, patch []byte, reverse bool) {
+	done := make(chan SteamParseDiffResult)
+	go StreamParseDiff(bytes.NewReader(patch), done, 0, 0, 0)
+	result := <-done
+	if result.Err != nil {
+		return
+	}
+
+	for _, rejection := range rejections {
+		if rejection.Line == 0 {
+			continue
+		}
+		for _, file := range result.Diff.Files {
+			if file.Name != rejection.Path && file.oldName != rejection.Path {
+				continue
+			}
+			for i, section := range file.Sections {
+				leftFirst, leftCount, rightFirst, rightCount, _, err := section.hunkRange()
+				if err != nil {
+					continue
+				}
+
+				first, count := leftFirst, leftCount
+				if reverse {
+					first, count = rightFirst, rightCount
+				}
+				// The line number in the section header precedes the range when empty
+				if count == 0 {
+					first--
+				}
+				if first == rejection.Line {
+					rejection.Hunk = i + 1
+					break
+				}
+			}
+		}
+	}
+}
+
+// ApplyPatch applies the patch to the working tree of the repository, or to
+// the index with Cached. It returns ErrPatchRejected along with the rejections
+// when the patch does not apply, in which case nothing is applied.
+func (r *Repository) ApplyPatch(patch io.Reader, opts ...ApplyPatchOptions) (*ApplyPatchResult, error) {
+	var opt ApplyPatchOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	data, err := io.ReadAll(patch)
+	if err != nil {
+		return nil, err
+	}
+
+	apply := func(check bool) (*ApplyPatchResult, error) {
+		cmd := NewCommand("apply").AddOptions(opt.CommandOptions)
+		if check {
+			cmd.AddArgs("--check")
+			// Report all rejected hunks instead of stopping at the first one of each
+			// file, which is not compatible with three-way merge.
+			if !opt.ThreeWay {
+				cmd.AddArgs("--reject")
+			}
+		}
+		if opt.ThreeWay {
+			cmd.AddArgs("--3way")
+		}
+		if opt.Cached {
+			cmd.AddArgs("--cached")
+		}
+		if opt.Reverse {
+			cmd.AddArgs("--reverse")
+		}
+		if opt.IndexFile != "" {
+			cmd.AddEnvs("GIT_INDEX_FILE=" + opt.IndexFile)
+		}
+
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+		err := cmd.RunInDirWithOptions(r.path, RunInDirOptions{
+			Stdin:  bytes.NewReader(data),
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+
+		rejections, conflicts := parseApplyOutput(stderr.Bytes())
+		if err != nil && len(rejections) == 0 && len(conflicts) == 0 {
+			return nil, concatenateError(err, stderr.String())
+		}
+		return &ApplyPatchResult{
+			Rejections: rejections,
+			Conflicts:  conflicts,
+		}, nil
+	}
+
+	result, err := apply(opt.Check)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Rejections) == 0 {
+		return result, nil
+	}
+
+	// Git stops at the first rejected hunk of each file when applying, check
+	// again to collect all of them.
+	if !opt.Check && !opt.ThreeWay {
+		result, err = apply(true)
+		if err != nil {
+			return nil, err
+		}
+	}
+	setRejectedHunks(result.Rejections, data, opt.Reverse)
+	return result, ErrPatchRejected
+}
@@ -0,0 +1,177 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseApplyOutput(t *testing.T) {
+	input := `Checking patch a.txt...
+error: while searching for:
+1
+error: not a real error
+3
+
+error: patch failed: a.txt:1
+error: patch failed: a.txt:12
+error: a.txt: patch does not apply
+Checking patch b.txt...
+error: b.txt: already exists in working directory
+Applied patch to 'c.txt' with conflicts.
+`
+	rejections, conflicts := parseApplyOutput([]byte(input))
+	assert.Equal(t, []*PatchRejection{
+		{Path: "a.txt", Line: 1, Reason: "patch failed"},
+		{Path: "a.txt", Line: 12, Reason: "patch failed"},
+		{Path: "b.txt", Reason: "already exists in working directory"},
+	}, rejections)
+	assert.Equal(t, []string{"c.txt"}, conflicts)
+
+	rejections, _ = parseApplyOutput([]byte("error: a.txt: patch does not apply\n"))
+	assert.Equal(t, []*PatchRejection{
+		{Path: "a.txt", Reason: "patch does not apply"},
+	}, rejections)
+}
+
+const testApplyPatch = `diff --git a/a.txt b/a.txt
+index 0ff3bbb..34689b6 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -12,7 +12,7 @@
+ 12
+ 13
+ 14
+-15
++fifteen
+ 16
+ 17
+ 18
+diff --git a/b.txt b/b.txt
+index 0ff3bbb..6c69c71 100644
+--- a/b.txt
++++ b/b.txt
+@@ -7,7 +7,7 @@
+ 7
+ 8
+ 9
+-10
++ten
+ 11
+ 12
+ 13
+`
+
+func TestRepository_ApplyPatch(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, strconv.Itoa(i))
+	}
+	writeFile := func(name string, lines []string) {
+		err := os.WriteFile(filepath.Join(r.Path(), name), []byte(strings.Join(lines, "\n")+"\n"), 0600)
+		require.NoError(t, err)
+	}
+	readFile := func(name string) string {
+		p, err := os.ReadFile(filepath.Join(r.Path(), name))
+		require.NoError(t, err)
+		return string(p)
+	}
+	writeFile("a.txt", lines)
+	writeFile("b.txt", lines)
+	require.NoError(t, r.Add(AddOptions{All: true}))
+	require.NoError(t, r.Commit(alice, "Add files"))
+	original := readFile("a.txt")
+
+	t.Run("check", func(t *testing.T) {
+		result, err := r.ApplyPatch(strings.NewReader(testApplyPatch), ApplyPatchOptions{Check: true})
+		require.NoError(t, err)
+		assert.Empty(t, result.Rejections)
+		assert.Equal(t, original, readFile("a.txt"))
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		_, err := r.ApplyPatch(strings.NewReader(testApplyPatch), ApplyPatchOptions{Cached: true})
+		require.NoError(t, err)
+		assert.Equal(t, original, readFile("a.txt"))
+
+		// The patch is in the index but not in the working tree
+		_, err = r.ApplyPatch(strings.NewReader(testApplyPatch), ApplyPatchOptions{Check: true, Cached: true, Reverse: true})
+		require.NoError(t, err)
+		_, err = r.ApplyPatch(strings.NewReader(testApplyPatch), ApplyPatchOptions{Check: true, Reverse: true})
+		assert.Equal(t, ErrPatchRejected, err)
+
+		_, err = r.ApplyPatch(strings.NewReader(testApplyPatch), ApplyPatchOptions{Cached: true, Reverse: true})
+		require.NoError(t, err)
+	})
+
+	t.Run("index file", func(t *testing.T) {
+		result, err := r.ApplyPatch(strings.NewReader(testApplyPatch), ApplyPatchOptions{
+			Check:     true,
+			Cached:    true,
+			IndexFile: filepath.Join(tempPath(), "index"),
+		})
+		assert.Equal(t, ErrPatchRejected, err)
+		assert.Equal(t, []*PatchRejection{
+			{Path: "a.txt", Reason: "does not exist in index"},
+			{Path: "b.txt", Reason: "does not exist in index"},
+		}, result.Rejections)
+	})
+
+	// Change lines around all hunks of the patch
+	lines[1] = "two"
+	lines[15] = "sixteen"
+	writeFile("a.txt", lines)
+	writeFile("b.txt", lines[:9])
+	require.NoError(t, r.Add(AddOptions{All: true}))
+	require.NoError(t, r.Commit(alice, "Change files"))
+	changed := readFile("a.txt")
+
+	t.Run("rejected", func(t *testing.T) {
+		for _, check := range []bool{true, false} {
+			result, err := r.ApplyPatch(strings.NewReader(testApplyPatch), ApplyPatchOptions{Check: check})
+			assert.Equal(t, ErrPatchRejected, err)
+			assert.Equal(t, []*PatchRejection{
+				{Path: "a.txt", Hunk: 1, Line: 1, Reason: "patch failed"},
+				{Path: "a.txt", Hunk: 2, Line: 12, Reason: "patch failed"},
+				{Path: "b.txt", Hunk: 1, Line: 7, Reason: "patch failed"},
+			}, result.Rejections)
+			assert.Equal(t, changed, readFile("a.txt"))
+		}
+	})
+
+	t.Run("three-way", func(t *testing.T) {
+		result, err := r.ApplyPatch(strings.NewReader(testApplyPatch), ApplyPatchOptions{ThreeWay: true})
+		require.NoError(t, err)
+		assert.Empty(t, result.Rejections)
+		assert.Equal(t, []string{"a.txt", "b.txt"}, result.Conflicts)
+		assert.Contains(t, readFile("a.txt"), "<<<<<<<")
+	})
+
+	t.Run("bad patch", func(t *testing.T) {
+		_, err := r.ApplyPatch(strings.NewReader("not a patch\n"))
+		assert.Error(t, err)
+		assert.NotEqual(t, ErrPatchRejected, err)
+	})
+}
@@ -6,12 +6,44 @@ package git
 
 import (
 	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// BlameRange is a range of lines to blame.
+type BlameRange struct {
+	// The 1-based line number of the first line.
+	Start int
+	// The 1-based line number of the last line, inclusive. The range extends to
+	// the end of the file when it is 0.
+	End int
+}
+
 // BlameOptions contains optional arguments for blaming a file.
 // Docs: https://git-scm.com/docs/git-blame
 type BlameOptions struct {
+	// The ranges of lines to blame. The whole file is blamed when not set.
+	Ranges []BlameRange
+	// Indicates whether to detect lines moved or copied within the same file in
+	// a commit.
+	DetectMoves bool
+	// Indicates whether to detect lines moved or copied from other files that
+	// were modified in the same commit.
+	DetectCopies bool
+	// The revisions to be ignored, e.g. commits that only reformat code. Lines
+	// changed by them are attributed to the commits that changed the lines
+	// before.
+	IgnoreRevs []string
+	// The path of the file that lists revisions to be ignored, one per line.
+	IgnoreRevsFile string
+	// Indicates whether to not honor the ".git-blame-ignore-revs" file in the
+	// tree of the revision, which lists revisions to be ignored by default.
+	SkipBlameIgnoreRevs bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -21,6 +53,137 @@ type BlameOptions struct {
 	CommandOptions
 }
 
+// blameIgnoreRevsFile is the conventional file in the tree that lists revisions
+// to be ignored by blame.
+const blameIgnoreRevsFile = ".git-blame-ignore-revs"
+
+// blameArgs returns the arguments of "git blame" except the output format, and
+// whether any revision is ignored. The returned cleanup function must be called
+// after running the command.
+func (r *Repository) blameArgs(rev, file string, opt BlameOptions) (args []string, ignoring bool, cleanup func(), err error) {
+	cleanup = func() {}
+
+	// NOTE: "git blame" does not support "--end-of-options", thus the revision
+	// must be checked to not be treated as an option.
+	if strings.HasPrefix(rev, "-") {
+		return nil, false, cleanup, fmt.Errorf("invalid revision: %q", rev)
+	}
+
+	for _, rng := range opt.Ranges {
+		if rng.End > 0 {
+			args = append(args, "-L", strconv.Itoa(rng.Start)+","+strconv.Itoa(rng.End))
+		} else {
+			args = append(args, "-L", strconv.Itoa(rng.Start)+",")
+		}
+	}
+	if opt.DetectMoves {
+		args = append(args, "-M")
+	}
+	if opt.DetectCopies {
+		args = append(args, "-C")
+	}
+	for _, ignoreRev := range opt.IgnoreRevs {
+		if strings.HasPrefix(ignoreRev, "-") {
+			return nil, false, cleanup, fmt.Errorf("invalid revision to ignore: %q", ignoreRev)
+		}
+		args = append(args, "--ignore-rev", ignoreRev)
+	}
+	if opt.IgnoreRevsFile != "" {
+		args = append(args, "--ignore-revs-file", opt.IgnoreRevsFile)
+	}
+	ignoring = len(opt.IgnoreRevs) > 0 || opt.IgnoreRevsFile != ""
+
+	if !opt.SkipBlameIgnoreRevs {
+		path, remove, err := r.blameIgnoreRevsFile(rev, opt)
+		if err != nil {
+			return nil, false, cleanup, err
+		}
+		if path != "" {
+			args = append(args, "--ignore-revs-file", path)
+			ignoring = true
+			cleanup = remove
+		}
+	}
+	return append(args, rev, "--", file), ignoring, cleanup, nil
+}
+
+// blameIgnoreRevsFile copies the ".git-blame-ignore-revs" file in the tree of
+// the revision to a temporary file, and returns its path along with the
+// function to remove it. The path is empty when there is no such file.
+func (r *Repository) blameIgnoreRevsFile(rev string, opt BlameOptions) (string, func(), error) {
+	// Look up the file with a single command as it is absent in most trees.
+	stdout, err := NewCommand("ls-tree", "-z").
+		AddOptions(opt.CommandOptions).
+		AddArgs(rev, "--", blameIgnoreRevsFile).
+		RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil {
+		return "", nil, err
+	}
+	entries, err := parseTree(&Tree{repo: r}, stdout, 0)
+	if err != nil {
+		return "", nil, err
+	} else if len(entries) == 0 || !entries[0].IsBlob() {
+		return "", nil, nil
+	}
+
+	f, err := os.CreateTemp("", "git-blame-ignore-revs-")
+	if err != nil {
+		return "", nil, err
+	}
+	remove := func() {
+		_ = os.Remove(f.Name())
+	}
+
+	err = entries[0].Blob().Pipeline(f, nil)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		remove()
+		return "", nil, err
+	}
+	return f.Name(), remove, nil
+}
+
+// runBlame runs the "git blame" command with given arguments, and parses the
+// output with the parse function which calls fn with each hunk.
+func (r *Repository) runBlame(
+	cmd *Command,
+	parse func(io.Reader, func(string) (*Commit, error), func(*BlameHunk) error) error,
+	opt BlameOptions,
+	fn func(*BlameHunk) error,
+) error {
+	commit := func(id string) (*Commit, error) {
+		return r.CatFileCommit(id, CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
+	}
+
+	stdout, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := parse(stdout, commit, fn)
+		// Stop the command when parsing fails, otherwise drain the rest of output
+		// to not block the command.
+		if err != nil {
+			_ = stdout.CloseWithError(err)
+		} else {
+			_, _ = io.Copy(io.Discard, stdout)
+		}
+		done <- err
+	}()
+
+	stderr := new(bytes.Buffer)
+	err := cmd.RunInDirPipelineWithTimeout(opt.Timeout, w, stderr, r.path)
+	_ = w.Close() // Close writer to exit parsing goroutine
+	parseErr := <-done
+	if parseErr != nil {
+		return parseErr
+	}
+	if err != nil {
+		return concatenateError(err, stderr.String())
+	}
+	return nil
+}
+
 // BlameFile returns blame results of the file with the given revision of the
 // repository.
 func (r *Repository) BlameFile(rev, file string, opts ...BlameOptions) (*Blame, error) {
@@ -29,33 +192,63 @@ func (r *Repository) BlameFile(rev, file string, opts ...BlameOptions) (*Blame,
 		opt = opts[0]
 	}
 
-	stdout, err := NewCommand("blame").
-		AddOptions(opt.CommandOptions).
-		AddArgs("-l", "-s", rev, "--", file).
-		RunInDirWithTimeout(opt.Timeout, r.path)
+	args, ignoring, cleanup, err := r.blameArgs(rev, file, opt)
+	defer cleanup()
 	if err != nil {
 		return nil, err
 	}
 
-	lines := bytes.Split(stdout, []byte{'\n'})
-	blame := &Blame{
-		lines: make([]*Commit, 0, len(lines)),
+	cmd := NewCommand("blame").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--porcelain")
+	parse := parseBlame
+	if ignoring {
+		// Lines attributed through ignored revisions are only marked in the default
+		// output format, which does not report previous commits.
+		cmd = NewCommand(
+			"-c", "blame.markIgnoredLines=true",
+			"-c", "blame.markUnblamableLines=true",
+			"-c", "blame.blankBoundary=false",
+			"blame",
+		).
+			AddOptions(opt.CommandOptions).
+			AddArgs("-l", "-s", "-n", "-f")
+		parse = parseBlameLines
 	}
-	for _, line := range lines {
-		if len(line) < 40 {
-			break
-		}
-		id := line[:40]
 
-		// Earliest commit is indicated by a leading "^"
-		if id[0] == '^' {
-			id = id[1:]
-		}
-		commit, err := r.CatFileCommit(string(id), CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
-		if err != nil {
-			return nil, err
-		}
-		blame.lines = append(blame.lines, commit)
+	blame := new(Blame)
+	err = r.runBlame(cmd.AddArgs(args...), parse, opt, func(hunk *BlameHunk) error {
+		blame.hunks = append(blame.hunks, hunk)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	sort.Slice(blame.hunks, func(i, j int) bool {
+		return blame.hunks[i].Line < blame.hunks[j].Line
+	})
 	return blame, nil
 }
+
+// BlameFileIncremental blames the file with the given revision of the
+// repository, and calls fn with each hunk as soon as it is found, which is
+// suitable for large files. Hunks are not in the order of lines, and they are
+// never marked as ignored. It stops and returns the error when fn returns one.
+func (r *Repository) BlameFileIncremental(rev, file string, fn func(*BlameHunk) error, opts ...BlameOptions) error {
+	var opt BlameOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	args, _, cleanup, err := r.blameArgs(rev, file, opt)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	cmd := NewCommand("blame").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--incremental").
+		AddArgs(args...)
+	return r.runBlame(cmd, parseBlame, opt, fn)
+}
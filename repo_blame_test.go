@@ -5,10 +5,14 @@
 package git
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRepository_BlameFile(t *testing.T) {
@@ -38,3 +42,173 @@ func TestRepository_BlameFile(t *testing.T) {
 		})
 	}
 }
+
+func TestRepository_BlameFile_History(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	commit := func(files map[string]string, message string) string {
+		t.Helper()
+		for name, content := range files {
+			path := filepath.Join(r.Path(), name)
+			if content == "" {
+				require.NoError(t, os.Remove(path))
+				continue
+			}
+			require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		}
+		require.NoError(t, r.Add(AddOptions{All: true}))
+		require.NoError(t, r.Commit(alice, message))
+		id, err := r.RevParse("HEAD")
+		require.NoError(t, err)
+		return id
+	}
+
+	const moved = "the first line that will be moved to another file\nthe second line that will be moved to another file\n"
+	first := commit(map[string]string{
+		"a.txt":      "1\n2\n3\n4\n",
+		"source.txt": "keep\n" + moved,
+	}, "Add files")
+	second := commit(map[string]string{"a.txt": "1\ntwo\n3\n4\n5\n"}, "Update a.txt")
+	commit(map[string]string{"a.txt": "", "b.txt": "1\ntwo\n3\n4\n5\n"}, "Rename a.txt")
+	third := commit(map[string]string{
+		"source.txt": "keep\n",
+		"target.txt": "new\n" + moved,
+	}, "Move lines")
+
+	t.Run("rename", func(t *testing.T) {
+		blame, err := r.BlameFile("HEAD", "b.txt")
+		require.NoError(t, err)
+
+		hunks := blame.Hunks()
+		require.Len(t, hunks, 4)
+		assert.Equal(t, first, hunks[0].Commit.ID.String())
+		assert.Equal(t, 1, hunks[0].Line)
+		assert.Equal(t, 1, hunks[0].NumLines)
+		assert.Equal(t, second, hunks[1].Commit.ID.String())
+		assert.Equal(t, "a.txt", hunks[1].Path)
+		assert.Equal(t, first, hunks[1].PreviousID.String())
+		assert.Equal(t, "a.txt", hunks[1].PreviousPath)
+		assert.Equal(t, 3, hunks[2].Line)
+		assert.Equal(t, 2, hunks[2].NumLines)
+		assert.Equal(t, "a.txt", hunks[3].Path)
+		assert.Equal(t, first, hunks[3].PreviousID.String())
+		assert.Equal(t, first, blame.Line(4).ID.String())
+		assert.Equal(t, second, blame.Line(5).ID.String())
+		assert.Nil(t, blame.Line(6))
+	})
+
+	t.Run("ranges", func(t *testing.T) {
+		blame, err := r.BlameFile("HEAD", "b.txt", BlameOptions{
+			Ranges: []BlameRange{{Start: 2, End: 3}, {Start: 5}},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, blame.Line(1))
+		assert.Equal(t, second, blame.Line(2).ID.String())
+		assert.Equal(t, first, blame.Line(3).ID.String())
+		assert.Nil(t, blame.Line(4))
+		assert.Equal(t, second, blame.Line(5).ID.String())
+	})
+
+	t.Run("copies", func(t *testing.T) {
+		blame, err := r.BlameFile("HEAD", "target.txt")
+		require.NoError(t, err)
+		assert.Equal(t, third, blame.Line(2).ID.String())
+
+		blame, err = r.BlameFile("HEAD", "target.txt", BlameOptions{DetectCopies: true})
+		require.NoError(t, err)
+		hunk := blame.Hunk(2)
+		require.NotNil(t, hunk)
+		assert.Equal(t, first, hunk.Commit.ID.String())
+		assert.Equal(t, "source.txt", hunk.Path)
+		assert.Equal(t, 2, hunk.OrigLine)
+		assert.Equal(t, 2, hunk.NumLines)
+	})
+
+	t.Run("incremental", func(t *testing.T) {
+		lines := 0
+		err := r.BlameFileIncremental("HEAD", "b.txt", func(hunk *BlameHunk) error {
+			lines += hunk.NumLines
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 5, lines)
+
+		errStop := errors.New("stop")
+		err = r.BlameFileIncremental("HEAD", "b.txt", func(*BlameHunk) error {
+			return errStop
+		})
+		assert.Equal(t, errStop, err)
+	})
+
+	t.Run("bad revision", func(t *testing.T) {
+		_, err := r.BlameFile("--output=/tmp/x", "b.txt")
+		assert.Error(t, err)
+	})
+}
+
+func TestRepository_BlameFile_IgnoreRevs(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	commit := func(name, content, message string) string {
+		t.Helper()
+		err := os.WriteFile(filepath.Join(r.Path(), name), []byte(content), 0600)
+		require.NoError(t, err)
+		require.NoError(t, r.Add(AddOptions{All: true}))
+		require.NoError(t, r.Commit(alice, message))
+		id, err := r.RevParse("HEAD")
+		require.NoError(t, err)
+		return id
+	}
+
+	original := commit("a.txt", "one\ntwo\nthree\n", "Add a.txt")
+	format := commit("a.txt", "one \ntwo\nthree \n", "Format a.txt")
+
+	blame, err := r.BlameFile("HEAD", "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, format, blame.Line(1).ID.String())
+	assert.False(t, blame.Hunk(1).Ignored)
+
+	blame, err = r.BlameFile("HEAD", "a.txt", BlameOptions{IgnoreRevs: []string{format}})
+	require.NoError(t, err)
+	for _, line := range []int{1, 2, 3} {
+		assert.Equal(t, original, blame.Line(line).ID.String(), "line %d", line)
+	}
+	assert.True(t, blame.Hunk(1).Ignored)
+	assert.False(t, blame.Hunk(2).Ignored)
+	assert.True(t, blame.Hunk(3).Ignored)
+
+	_, err = r.BlameFile("HEAD", "a.txt", BlameOptions{IgnoreRevs: []string{"--output=/tmp/x"}})
+	assert.Error(t, err)
+
+	// The file in the tree of the revision is honored by default
+	commit(blameIgnoreRevsFile, "# Formatting\n"+format+"\n", "Ignore formatting")
+
+	blame, err = r.BlameFile("HEAD", "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, original, blame.Line(1).ID.String())
+	assert.True(t, blame.Hunk(1).Ignored)
+
+	var ids []string
+	err = r.BlameFileIncremental("HEAD", "a.txt", func(hunk *BlameHunk) error {
+		ids = append(ids, hunk.Commit.ID.String())
+		return nil
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, ids, format)
+
+	blame, err = r.BlameFile("HEAD", "a.txt", BlameOptions{SkipBlameIgnoreRevs: true})
+	require.NoError(t, err)
+	assert.Equal(t, format, blame.Line(1).ID.String())
+	assert.False(t, blame.Hunk(1).Ignored)
+
+	// The file does not exist in the tree of older revisions
+	blame, err = r.BlameFile(format, "a.txt")
+	require.NoError(t, err)
+	assert.Equal(t, format, blame.Line(1).ID.String())
+}
@@ -18,6 +18,9 @@ import (
 // message.
 func parseCommit(data []byte) (*Commit, error) {
 	commit := new(Commit)
+	// The positions of signature headers, which are excluded from the signed
+	// payload.
+	var signatureHeaders [][2]int
 	// we now have the contents of the commit object. Let's investigate.
 	nextline := 0
 loop:
@@ -27,6 +30,9 @@ loop:
 		case eol > 0:
 			line := data[nextline : nextline+eol]
 			spacepos := bytes.IndexByte(line, ' ')
+			if spacepos < 0 {
+				spacepos = len(line)
+			}
 			reftype := line[:spacepos]
 			switch string(reftype) {
 			case "tree", "object":
@@ -54,6 +60,46 @@ loop:
 					return nil, err
 				}
 				commit.Committer = sig
+			case "encoding":
+				commit.encoding = string(line[spacepos+1:])
+			case "gpgsig":
+				// The signature continues with lines that start with a space, and the
+				// signed payload is the commit object without the whole header.
+				value, end := parseMultilineHeader(data, nextline+spacepos+1)
+				commit.signature = &ObjectSignature{Signature: value}
+				signatureHeaders = append(signatureHeaders, [2]int{nextline, end})
+				nextline = end
+				continue
+			case "mergetag":
+				// The tag object of the merged tag is embedded as the value.
+				value, end := parseMultilineHeader(data, nextline+spacepos+1)
+				tag, err := parseTag([]byte(value))
+				if err != nil {
+					return nil, fmt.Errorf("parse mergetag: %v", err)
+				}
+				tag.typ = ObjectTag
+				tag.id = hashObject(ObjectTag, []byte(value))
+				commit.mergeTags = append(commit.mergeTags, tag)
+				nextline = end
+				continue
+			default:
+				value := ""
+				end := nextline + eol + 1
+				if spacepos < len(line) {
+					value, end = parseMultilineHeader(data, nextline+spacepos+1)
+					value = strings.TrimSuffix(value, "\n")
+				}
+				commit.extraHeaders = append(commit.extraHeaders, &CommitHeader{
+					Key:   string(reftype),
+					Value: value,
+				})
+				// The signature of the SHA-256 form of the commit is not part of the
+				// payload signed by "gpgsig" either.
+				if string(reftype) == "gpgsig-sha256" {
+					signatureHeaders = append(signatureHeaders, [2]int{nextline, end})
+				}
+				nextline = end
+				continue
 			}
 			nextline += eol + 1
 		case eol == 0:
@@ -63,13 +109,61 @@ loop:
 			break loop
 		}
 	}
+
+	if commit.signature != nil {
+		var payload strings.Builder
+		pos := 0
+		for _, h := range signatureHeaders {
+			payload.Write(data[pos:h[0]])
+			pos = h[1]
+		}
+		payload.Write(data[pos:])
+		commit.signature.Payload = payload.String()
+	}
+
+	// Messages and names are stored in the encoding specified by the header,
+	// transcode them to UTF-8.
+	if commit.encoding != "" {
+		commit.Message = decodeToUTF8(commit.encoding, commit.Message)
+		for _, sig := range []*Signature{commit.Author, commit.Committer} {
+			if sig != nil {
+				sig.Name = decodeToUTF8(commit.encoding, sig.Name)
+			}
+		}
+	}
 	return commit, nil
 }
 
+// parseMultilineHeader parses the value of a header in the raw data of an
+// object starting from given position, whose continuation lines start with a
+// space. It returns the value without the leading spaces of continuation lines,
+// and the position right after the header.
+func parseMultilineHeader(data []byte, start int) (string, int) {
+	var value bytes.Buffer
+	pos := start
+	for {
+		eol := bytes.IndexByte(data[pos:], '\n')
+		if eol < 0 {
+			value.Write(data[pos:])
+			return value.String(), len(data)
+		}
+		value.Write(data[pos : pos+eol+1])
+		pos += eol + 1
+
+		if pos >= len(data) || data[pos] != ' ' {
+			return value.String(), pos
+		}
+		pos++ // Skip the leading space
+	}
+}
+
 // CatFileCommitOptions contains optional arguments for verifying the objects.
 //
 // Docs: https://git-scm.com/docs/git-cat-file#Documentation/git-cat-file.txt-lttypegt
 type CatFileCommitOptions struct {
+	// Indicates whether to resolve author and committer to their canonical
+	// identities using the mailmap of the repository.
+	UseMailmap bool
 	// The timeout duration before giving up for each shell command execution.
 	// The default timeout duration will be used when not supplied.
 	//
@@ -88,6 +182,24 @@ func (r *Repository) CatFileCommit(rev string, opts ...CatFileCommitOptions) (*C
 		opt = opts[0]
 	}
 
+	c, err := r.catFileCommit(rev, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.UseMailmap {
+		m, err := r.Mailmap(MailmapOptions{CommandOptions: CommandOptions{Timeout: opt.Timeout}})
+		if err != nil {
+			return nil, fmt.Errorf("load mailmap: %v", err)
+		}
+		c = c.withMailmap(m)
+	}
+	return c, nil
+}
+
+// catFileCommit returns the commit corresponding to the given revision of the
+// repository as it is stored, with the commit being cached.
+func (r *Repository) catFileCommit(rev string, opt CatFileCommitOptions) (*Commit, error) {
 	cache, ok := r.cachedCommits.Get(rev)
 	if ok {
 		log("Cached commit hit: %s", rev)
@@ -113,6 +225,9 @@ func (r *Repository) CatFileCommit(rev string, opts ...CatFileCommitOptions) (*C
 	}
 	c.repo = r
 	c.ID = MustIDFromString(commitID)
+	for _, tag := range c.mergeTags {
+		tag.repo = r
+	}
 
 	r.cachedCommits.Set(commitID, c)
 	return c, nil
@@ -175,6 +290,9 @@ type LogOptions struct {
 	RegexpIgnoreCase bool
 	// The relative path of the repository.
 	Path string
+	// Indicates whether to resolve authors and committers to their canonical
+	// identities using the mailmap of the repository.
+	UseMailmap bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	Timeout time.Duration
@@ -246,7 +364,22 @@ func (r *Repository) Log(rev string, opts ...LogOptions) ([]*Commit, error) {
 	if err != nil {
 		return nil, err
 	}
-	return r.parsePrettyFormatLogToList(opt.Timeout, stdout)
+
+	commits, err := r.parsePrettyFormatLogToList(opt.Timeout, stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.UseMailmap && len(commits) > 0 {
+		m, err := r.Mailmap(MailmapOptions{CommandOptions: CommandOptions{Timeout: opt.Timeout}})
+		if err != nil {
+			return nil, fmt.Errorf("load mailmap: %v", err)
+		}
+		for i := range commits {
+			commits[i] = commits[i].withMailmap(m)
+		}
+	}
+	return commits, nil
 }
 
 // CommitByRevisionOptions contains optional arguments for getting a commit.
@@ -255,6 +388,9 @@ func (r *Repository) Log(rev string, opts ...LogOptions) ([]*Commit, error) {
 type CommitByRevisionOptions struct {
 	// The relative path of the repository.
 	Path string
+	// Indicates whether to resolve authors and committers to their canonical
+	// identities using the mailmap of the repository.
+	UseMailmap bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	Timeout time.Duration
@@ -272,6 +408,7 @@ func (r *Repository) CommitByRevision(rev string, opts ...CommitByRevisionOption
 	commits, err := r.Log(rev, LogOptions{
 		MaxCount:       1,
 		Path:           opt.Path,
+		UseMailmap:     opt.UseMailmap,
 		Timeout:        opt.Timeout,
 		CommandOptions: opt.CommandOptions,
 	})
@@ -293,6 +430,9 @@ func (r *Repository) CommitByRevision(rev string, opts ...CommitByRevisionOption
 type CommitsByPageOptions struct {
 	// The relative path of the repository.
 	Path string
+	// Indicates whether to resolve authors and committers to their canonical
+	// identities using the mailmap of the repository.
+	UseMailmap bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	Timeout time.Duration
@@ -312,6 +452,7 @@ func (r *Repository) CommitsByPage(rev string, page, size int, opts ...CommitsBy
 		MaxCount:       size,
 		Skip:           (page - 1) * size,
 		Path:           opt.Path,
+		UseMailmap:     opt.UseMailmap,
 		Timeout:        opt.Timeout,
 		CommandOptions: opt.CommandOptions,
 	})
@@ -380,6 +521,197 @@ func (r *Repository) CommitsSince(rev string, since time.Time, opts ...CommitsSi
 	})
 }
 
+// FileHistoryCommit contains a commit in the history of a file, along with the
+// change made to the file in the commit.
+type FileHistoryCommit struct {
+	*Commit
+	// The path of the file in the state of the commit.
+	Path string
+	// The path of the file before the commit, only set when the file was renamed
+	// in the commit, or the path of the source file when the file was copied.
+	OldPath string
+	// The type of the change made to the file in the commit. It is zero when Git
+	// shows no change for the commit, e.g. a merge commit.
+	Type DiffFileType
+}
+
+// FileHistoryOptions contains optional arguments for listing the history of a
+// file.
+//
+// Docs: https://git-scm.com/docs/git-log#Documentation/git-log.txt---follow
+type FileHistoryOptions struct {
+	// The maximum number of commits to output.
+	MaxCount int
+	// The number commits skipped before starting to show the commit output.
+	Skip int
+	// The similarity threshold (0-100) in percentage to detect renames. Git's
+	// default (50) is used when not set.
+	RenameThreshold int
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// nameStatusType returns the type of the change by given status letter of the
+// "--name-status" output.
+func nameStatusType(status byte) DiffFileType {
+	switch status {
+	case 'A':
+		return DiffFileAdd
+	case 'C':
+		return DiffFileCopy
+	case 'D':
+		return DiffFileDelete
+	case 'R':
+		return DiffFileRename
+	default:
+		return DiffFileChange
+	}
+}
+
+// fileHistoryLogFormat is the format of "git log" for listing the history of a
+// file. Each commit starts with a "\x01", followed by the NUL-separated commit
+// ID, tree ID, parent IDs, author, committer and message.
+const fileHistoryLogFormat = "tformat:%x01%H%x00%T%x00%P%x00%an <%ae> %ad%x00%cn <%ce> %cd%x00%B%x00"
+
+// parseFileHistory parses the output of "git log --follow --name-status -z
+// --date=raw" with the fileHistoryLogFormat, and returns commits with the file
+// changes, e.g.
+//
+//	\x01<commit>\x00<tree>\x00<parents>\x00<author>\x00<committer>\x00<message>\x00\x00\nM\x00<path>\x00
+func parseFileHistory(data []byte) ([]*FileHistoryCommit, error) {
+	var (
+		commits []*FileHistoryCommit
+		current *FileHistoryCommit
+	)
+	fields := bytes.Split(data, []byte{0})
+	for i := 0; i < len(fields); i++ {
+		// Fields of the commit are consumed together, so a field that starts with
+		// "\x01" begins a new commit. Note that a commit (e.g. a merge commit) may
+		// have no status at all.
+		if len(fields[i]) > 0 && fields[i][0] == 1 {
+			if i+5 >= len(fields) {
+				return nil, fmt.Errorf("malformed history: incomplete commit %q", fields[i][1:])
+			}
+			c, err := parseFileHistoryCommit(fields[i][1:], fields[i+1:i+6])
+			if err != nil {
+				return nil, err
+			}
+			current = &FileHistoryCommit{Commit: c}
+			commits = append(commits, current)
+			i += 5
+			continue
+		}
+
+		field := string(bytes.TrimLeft(fields[i], "\n"))
+		if field == "" {
+			continue
+		} else if current == nil {
+			return nil, fmt.Errorf("malformed history: expect commit but got %q", field)
+		}
+
+		status := field[0]
+		current.Type = nameStatusType(status)
+
+		if status == 'R' || status == 'C' {
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("malformed history: missing paths for %q", field)
+			}
+			current.OldPath = string(fields[i+1])
+			current.Path = string(fields[i+2])
+			i += 2
+		} else {
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("malformed history: missing path for %q", field)
+			}
+			current.Path = string(fields[i+1])
+			i++
+		}
+	}
+	return commits, nil
+}
+
+// parseFileHistoryCommit parses the commit with given ID and the rest fields
+// of the fileHistoryLogFormat.
+func parseFileHistoryCommit(id []byte, fields [][]byte) (*Commit, error) {
+	commitID, err := NewIDFromString(string(id))
+	if err != nil {
+		return nil, err
+	}
+	treeID, err := NewIDFromString(string(fields[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Commit{
+		ID:      commitID,
+		Message: string(fields[4]),
+		Tree:    &Tree{id: treeID},
+	}
+	for _, parent := range strings.Fields(string(fields[1])) {
+		parentID, err := NewIDFromString(parent)
+		if err != nil {
+			return nil, err
+		}
+		c.parents = append(c.parents, parentID)
+	}
+
+	c.Author, err = parseSignature(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	c.Committer, err = parseSignature(fields[3])
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// FileHistory returns a list of commits that changed the file in given path in
+// the state of given revision, following the file across renames. The returned
+// list is in reverse chronological order.
+//
+// Commits are loaded from the same "git log" output, thus only the ID, tree,
+// parents, author, committer and message of the commits are populated, but not
+// the encoding, signature, merge tags and extra headers.
+func (r *Repository) FileHistory(rev, path string, opts ...FileHistoryOptions) ([]*FileHistoryCommit, error) {
+	var opt FileHistoryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("log").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--follow", "--name-status", "-z", "--date=raw", "--pretty="+fileHistoryLogFormat)
+	if opt.RenameThreshold > 0 {
+		cmd.AddArgs("-M" + strconv.Itoa(opt.RenameThreshold) + "%")
+	}
+	// NOTE: "--skip" does not work well together with "--follow", thus we skip
+	// commits by ourselves.
+	if opt.MaxCount > 0 {
+		cmd.AddArgs("--max-count=" + strconv.Itoa(opt.Skip+opt.MaxCount))
+	}
+	cmd.AddArgs("--end-of-options", rev, "--", escapePath(path))
+
+	stdout, err := cmd.RunInDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := parseFileHistory(stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	if opt.Skip >= len(commits) {
+		return []*FileHistoryCommit{}, nil
+	}
+	commits = commits[opt.Skip:]
+	for i := range commits {
+		commits[i].repo = r
+	}
+	return commits, nil
+}
+
 // DiffNameOnlyOptions contains optional arguments for listing changed files.
 //
 // Docs: https://git-scm.com/docs/git-diff#Documentation/git-diff.txt---name-only
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_escapePath(t *testing.T) {
@@ -37,6 +38,143 @@ func Test_escapePath(t *testing.T) {
 	}
 }
 
+func Test_parseCommit_Headers(t *testing.T) {
+	data := "tree aaff74984cccd156a469afa7d9ab10e4777beb24\n" +
+		"parent 755fd577edcfd9209d0ac072eed3b022cbe4d39b\n" +
+		"parent 0eedd79eba4394bbef888c804e899df4cf1a1e12\n" +
+		"author Ren\xe9 <rene@example.com> 1581250680 +0800\n" +
+		"committer Bob <bob@example.com> 1581250690 -0530\n" +
+		"encoding ISO-8859-1\n" +
+		"mergetag object 755fd577edcfd9209d0ac072eed3b022cbe4d39b\n" +
+		" type commit\n" +
+		" tag v1.1.0\n" +
+		" tagger Alice <alice@example.com> 1581250680 +0800\n" +
+		" \n" +
+		" Release v1.1.0\n" +
+		"HG:extra rebase_source:4c7e3b1\n" +
+		"gpgsig-sha256 -----BEGIN PGP SIGNATURE-----\n" +
+		" \n" +
+		" iQEzBAABCAAdFiEE\n" +
+		" -----END PGP SIGNATURE-----\n" +
+		"nonvalue\n" +
+		"\n" +
+		"Merge tag 'v1.1.0' caf\xe9\n"
+	c, err := parseCommit([]byte(data))
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, c.ParentsCount())
+	assert.Equal(t, "ISO-8859-1", c.Encoding())
+	assert.Equal(t, "Merge tag 'v1.1.0' café\n", c.Message)
+	assert.Equal(t, "René", c.Author.Name)
+
+	// The original timezone offsets are preserved
+	_, offset := c.Author.When.Zone()
+	assert.Equal(t, 8*3600, offset)
+	assert.Equal(t, "2020-02-09 20:18:00 +0800", c.Author.When.Format("2006-01-02 15:04:05 -0700"))
+	_, offset = c.Committer.When.Zone()
+	assert.Equal(t, -(5*3600 + 30*60), offset)
+	assert.Equal(t, int64(1581250690), c.Committer.When.Unix())
+
+	require.Len(t, c.MergeTags(), 1)
+	tag := c.MergeTags()[0]
+	assert.Equal(t, "00c007ba123cb159ce372d3992cc220686a41875", tag.ID().String())
+	assert.Equal(t, ObjectTag, tag.Type())
+	assert.Equal(t, "755fd577edcfd9209d0ac072eed3b022cbe4d39b", tag.CommitID().String())
+	assert.Equal(t, "Alice", tag.Tagger().Name)
+	assert.Equal(t, "Release v1.1.0\n", tag.Message())
+
+	assert.Equal(t, []*CommitHeader{
+		{Key: "HG:extra", Value: "rebase_source:4c7e3b1"},
+		{Key: "gpgsig-sha256", Value: "-----BEGIN PGP SIGNATURE-----\n\niQEzBAABCAAdFiEE\n-----END PGP SIGNATURE-----"},
+		{Key: "nonvalue", Value: ""},
+	}, c.ExtraHeaders())
+	assert.Nil(t, c.Signature())
+
+	t.Run("unknown encoding", func(t *testing.T) {
+		c, err := parseCommit([]byte("tree aaff74984cccd156a469afa7d9ab10e4777beb24\nencoding x-unknown\n\ncaf\xe9\n"))
+		require.NoError(t, err)
+		assert.Equal(t, "x-unknown", c.Encoding())
+		assert.Equal(t, "caf\xe9\n", c.Message)
+	})
+}
+
+func Test_parseCommit_Signature(t *testing.T) {
+	data := `tree aaff74984cccd156a469afa7d9ab10e4777beb24
+author Alice <alice@example.com> 1792327702 +0000
+committer Alice <alice@example.com> 1792327702 +0000
+gpgsig -----BEGIN SSH SIGNATURE-----
+ U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAg//lrNehG5n1EJUd0J+IEZ+gwhY
+ 
+ -----END SSH SIGNATURE-----
+
+signed
+`
+	c, err := parseCommit([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Alice", c.Committer.Name)
+	assert.Equal(t, "signed\n", c.Message)
+	if assert.NotNil(t, c.Signature()) {
+		assert.Equal(t, SignatureFormatSSH, c.Signature().Format())
+		assert.Equal(t, `-----BEGIN SSH SIGNATURE-----
+U1NIU0lHAAAAAQAAADMAAAALc3NoLWVkMjU1MTkAAAAg//lrNehG5n1EJUd0J+IEZ+gwhY
+
+-----END SSH SIGNATURE-----
+`, c.Signature().Signature)
+		assert.Equal(t, `tree aaff74984cccd156a469afa7d9ab10e4777beb24
+author Alice <alice@example.com> 1792327702 +0000
+committer Alice <alice@example.com> 1792327702 +0000
+
+signed
+`, c.Signature().Payload)
+	}
+
+	t.Run("both object formats", func(t *testing.T) {
+		data := `tree aaff74984cccd156a469afa7d9ab10e4777beb24
+author Alice <alice@example.com> 1792327702 +0000
+committer Alice <alice@example.com> 1792327702 +0000
+gpgsig -----BEGIN PGP SIGNATURE-----
+ 
+ iQEzBAABCAAdFiEE
+ -----END PGP SIGNATURE-----
+gpgsig-sha256 -----BEGIN PGP SIGNATURE-----
+ 
+ iQEzBAABCAAdFiEF
+ -----END PGP SIGNATURE-----
+
+signed
+`
+		c, err := parseCommit([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if assert.NotNil(t, c.Signature()) {
+			assert.Equal(t, SignatureFormatOpenPGP, c.Signature().Format())
+			assert.Equal(t, "-----BEGIN PGP SIGNATURE-----\n\niQEzBAABCAAdFiEE\n-----END PGP SIGNATURE-----\n", c.Signature().Signature)
+			assert.Equal(t, `tree aaff74984cccd156a469afa7d9ab10e4777beb24
+author Alice <alice@example.com> 1792327702 +0000
+committer Alice <alice@example.com> 1792327702 +0000
+
+signed
+`, c.Signature().Payload)
+		}
+		if assert.Len(t, c.ExtraHeaders(), 1) {
+			assert.Equal(t, "gpgsig-sha256", c.ExtraHeaders()[0].Key)
+		}
+	})
+
+	t.Run("not signed", func(t *testing.T) {
+		c, err := parseCommit([]byte("tree aaff74984cccd156a469afa7d9ab10e4777beb24\n\nunsigned\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Nil(t, c.Signature())
+	})
+}
+
 func TestRepository_CatFileCommit(t *testing.T) {
 	t.Run("invalid revision", func(t *testing.T) {
 		c, err := testrepo.CatFileCommit("bad_revision")
@@ -190,6 +328,130 @@ func TestRepository_CommitsSince(t *testing.T) {
 	}
 }
 
+func Test_parseFileHistory(t *testing.T) {
+	header := func(id, parents, message string) string {
+		return "\x01" + id + "\x00" +
+			"b3c0d8b6e8ed6aa1c3ef4cfeaf2da1d3e5f8c4d7\x00" +
+			parents + "\x00" +
+			"alice <alice@example.com> 1700000000 +0200\x00" +
+			"bob <bob@example.com> 1700000100 +0000\x00" +
+			message + "\x00\x00"
+	}
+	input := header("d1c91cfcb80c7ff5b92e9d3997a6087af7e1ad8c", "7c47e032628bfc9f3e00a7fe91cb93cfda0db0f5", "Update b\n") + "\nM\x00b.txt\x00" +
+		header("7c47e032628bfc9f3e00a7fe91cb93cfda0db0f5", "0a7d5a4b4ba1fd9a8e0ef86bff4e3b7ff0f6e1a2", "Rename\n") + "\nR100\x00a.txt\x00b.txt\x00" +
+		header("0a7d5a4b4ba1fd9a8e0ef86bff4e3b7ff0f6e1a2", "e98f87185fb83615133d9857c3c1f9e774fdae74", "Copy\n") + "\nC075\x00c.txt\x00a.txt\x00" +
+		header("e98f87185fb83615133d9857c3c1f9e774fdae74", "f1b5a68badd65ae8bdbf7efb7bfb20541538d36c 0a7d5a4b4ba1fd9a8e0ef86bff4e3b7ff0f6e1a2", "Merge\n") +
+		header("f1b5a68badd65ae8bdbf7efb7bfb20541538d36c", "", "Add a\n\nWith body\n") + "\nA\x00a.txt\x00"
+
+	commits, err := parseFileHistory([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []*FileHistoryCommit
+	for _, c := range commits {
+		got = append(got, &FileHistoryCommit{Path: c.Path, OldPath: c.OldPath, Type: c.Type})
+	}
+	assert.Equal(t, []*FileHistoryCommit{
+		{Path: "b.txt", Type: DiffFileChange},
+		{Path: "b.txt", OldPath: "a.txt", Type: DiffFileRename},
+		{Path: "a.txt", OldPath: "c.txt", Type: DiffFileCopy},
+		{},
+		{Path: "a.txt", Type: DiffFileAdd},
+	}, got)
+
+	merge := commits[3].Commit
+	assert.Equal(t, "e98f87185fb83615133d9857c3c1f9e774fdae74", merge.ID.String())
+	assert.Equal(t, "b3c0d8b6e8ed6aa1c3ef4cfeaf2da1d3e5f8c4d7", merge.Tree.id.String())
+	assert.Equal(t, 2, merge.ParentsCount())
+	assert.Equal(t, "alice", merge.Author.Name)
+	assert.Equal(t, "alice@example.com", merge.Author.Email)
+	assert.Equal(t, int64(1700000000), merge.Author.When.Unix())
+	assert.Equal(t, "bob", merge.Committer.Name)
+	assert.Equal(t, "Merge\n", merge.Message)
+
+	assert.Equal(t, 0, commits[4].ParentsCount())
+	assert.Equal(t, "Add a\n\nWith body\n", commits[4].Message)
+
+	_, err = parseFileHistory([]byte("\nM\x00b.txt\x00"))
+	assert.Error(t, err)
+	_, err = parseFileHistory([]byte("\x01d1c91cfcb80c7ff5b92e9d3997a6087af7e1ad8c\x00"))
+	assert.Error(t, err)
+}
+
+func TestRepository_FileHistory(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	before, err := r.FileHistory("master", "README.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rename the file to see if the history is followed
+	if err = r.Move("README.txt", "README.md"); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Commit(&Signature{Name: "alice", Email: "alice@example.com"}, "Rename README"); err != nil {
+		t.Fatal(err)
+	}
+
+	commits, err := r.FileHistory("master", "README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !assert.Len(t, commits, len(before)+1) {
+		return
+	}
+
+	assert.Equal(t, "Rename README", commits[0].Summary())
+	assert.Equal(t, DiffFileRename, commits[0].Type)
+	assert.Equal(t, "README.md", commits[0].Path)
+	assert.Equal(t, "README.txt", commits[0].OldPath)
+	for i := range before {
+		assert.Equal(t, before[i].ID.String(), commits[i+1].ID.String())
+		assert.Equal(t, "README.txt", commits[i+1].Path)
+	}
+	assert.Equal(t, DiffFileAdd, commits[len(commits)-1].Type)
+
+	// Commits should be the same as loaded individually
+	for _, c := range commits {
+		exp, err := r.CatFileCommit(c.ID.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, exp.Tree.id.String(), c.Tree.id.String())
+		assert.Equal(t, exp.ParentsCount(), c.ParentsCount())
+		assert.Equal(t, exp.Author, c.Author)
+		assert.Equal(t, exp.Committer, c.Committer)
+		assert.Equal(t, exp.Message, c.Message)
+	}
+
+	t.Run("paging", func(t *testing.T) {
+		page, err := r.FileHistory("master", "README.md", FileHistoryOptions{
+			MaxCount: 1,
+			Skip:     1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if assert.Len(t, page, 1) {
+			assert.Equal(t, commits[1].ID.String(), page[0].ID.String())
+		}
+
+		page, err = r.FileHistory("master", "README.md", FileHistoryOptions{
+			Skip: len(commits),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Empty(t, page)
+	})
+}
+
 func TestRepository_DiffNameOnly(t *testing.T) {
 	tests := []struct {
 		base     string
@@ -6,11 +6,27 @@ package git
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// DiffAlgorithm is the algorithm to compute diff.
+type DiffAlgorithm string
+
+// A list of diff algorithms.
+//
+// Docs: https://git-scm.com/docs/git-diff#Documentation/git-diff.txt---diff-algorithmpatienceminimalhistogrammyers
+const (
+	DiffAlgorithmMyers     DiffAlgorithm = "myers"
+	DiffAlgorithmMinimal   DiffAlgorithm = "minimal"
+	DiffAlgorithmPatience  DiffAlgorithm = "patience"
+	DiffAlgorithmHistogram DiffAlgorithm = "histogram"
+)
+
 // DiffOptions contains optional arguments for parsing diff.
 //
 // Docs: https://git-scm.com/docs/git-diff#Documentation/git-diff.txt---full-index
@@ -18,6 +34,33 @@ type DiffOptions struct {
 	// The commit ID to used for computing diff between a range of commits (base,
 	// revision]. When not set, only computes diff for a single commit at revision.
 	Base string
+	// Indicates whether to compute the combined diff (i.e. "--cc") against all
+	// parents when the commit at revision is a merge commit. It is ignored when
+	// Base is set.
+	Combined bool
+	// The similarity threshold (0-100) in percentage to detect renames. Git's
+	// default (50) is used when not set.
+	RenameThreshold int
+	// Indicates whether to detect copies in addition to renames. Only files
+	// modified in the same change are considered as sources of copies.
+	DetectCopies bool
+	// The similarity threshold (0-100) in percentage to detect copies. Git's
+	// default (50) is used when not set. It implies DetectCopies.
+	CopyThreshold int
+	// Indicates whether to ignore whitespace when comparing lines.
+	IgnoreAllSpace bool
+	// Indicates whether to ignore changes whose lines are all blank.
+	IgnoreBlankLines bool
+	// The algorithm to compute diff. Git's default is used when not set.
+	Algorithm DiffAlgorithm
+	// The number of context lines around changes. Git's default (3) is used when
+	// not set, and no context line is shown when negative.
+	ContextLines int
+	// Indicates whether to show the whole function as context lines for each
+	// change.
+	FunctionContext bool
+	// The specific pathspecs to limit the diff to.
+	Pathspecs []string
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -27,6 +70,39 @@ type DiffOptions struct {
 	CommandOptions
 }
 
+// args returns the arguments to compute diff with the options.
+func (opt DiffOptions) args() []string {
+	args := make([]string, 0, 8)
+	if opt.RenameThreshold > 0 {
+		args = append(args, "-M"+strconv.Itoa(opt.RenameThreshold)+"%")
+	} else {
+		args = append(args, "-M")
+	}
+	if opt.CopyThreshold > 0 {
+		args = append(args, "-C"+strconv.Itoa(opt.CopyThreshold)+"%")
+	} else if opt.DetectCopies {
+		args = append(args, "-C")
+	}
+	if opt.IgnoreAllSpace {
+		args = append(args, "--ignore-all-space")
+	}
+	if opt.IgnoreBlankLines {
+		args = append(args, "--ignore-blank-lines")
+	}
+	if opt.Algorithm != "" {
+		args = append(args, "--diff-algorithm="+string(opt.Algorithm))
+	}
+	if opt.ContextLines > 0 {
+		args = append(args, "--unified="+strconv.Itoa(opt.ContextLines))
+	} else if opt.ContextLines < 0 {
+		args = append(args, "--unified=0")
+	}
+	if opt.FunctionContext {
+		args = append(args, "--function-context")
+	}
+	return args
+}
+
 // Diff returns a parsed diff object between given commits of the repository.
 func (r *Repository) Diff(rev string, maxFiles, maxFileLines, maxLineChars int, opts ...DiffOptions) (*Diff, error) {
 	var opt DiffOptions
@@ -45,7 +121,15 @@ func (r *Repository) Diff(rev string, maxFiles, maxFileLines, maxLineChars int,
 		if commit.ParentsCount() == 0 {
 			cmd = cmd.AddArgs("show").
 				AddOptions(opt.CommandOptions).
-				AddArgs("--full-index", "--end-of-options", rev)
+				AddArgs("--full-index").
+				AddArgs(opt.args()...).
+				AddArgs("--end-of-options", rev)
+		} else if opt.Combined && commit.ParentsCount() > 1 {
+			cmd = cmd.AddArgs("show").
+				AddOptions(opt.CommandOptions).
+				AddArgs("--full-index", "--cc", "--format=").
+				AddArgs(opt.args()...).
+				AddArgs("--end-of-options", rev)
 		} else {
 			c, err := commit.Parent(0)
 			if err != nil {
@@ -53,12 +137,20 @@ func (r *Repository) Diff(rev string, maxFiles, maxFileLines, maxLineChars int,
 			}
 			cmd = cmd.AddArgs("diff").
 				AddOptions(opt.CommandOptions).
-				AddArgs("--full-index", "-M", c.ID.String(), "--end-of-options", rev)
+				AddArgs("--full-index").
+				AddArgs(opt.args()...).
+				AddArgs(c.ID.String(), "--end-of-options", rev)
 		}
 	} else {
 		cmd = cmd.AddArgs("diff").
 			AddOptions(opt.CommandOptions).
-			AddArgs("--full-index", "-M", opt.Base, "--end-of-options", rev)
+			AddArgs("--full-index").
+			AddArgs(opt.args()...).
+			AddArgs(opt.Base, "--end-of-options", rev)
+	}
+	if len(opt.Pathspecs) > 0 {
+		cmd.AddArgs("--")
+		cmd.AddArgs(opt.Pathspecs...)
 	}
 
 	stdout, w := io.Pipe()
@@ -76,6 +168,546 @@ func (r *Repository) Diff(rev string, maxFiles, maxFileLines, maxLineChars int,
 	return result.Diff, result.Err
 }
 
+// DiffStatFile contains the line statistics of a file in diff.
+type DiffStatFile struct {
+	// The path of the file.
+	Path string
+	// The path of the file before renaming, only set when the file was renamed.
+	OldPath string
+	// The number of added lines.
+	Additions int
+	// The number of deleted lines.
+	Deletions int
+	// Indicates whether the file is in binary format, which has no line
+	// statistics.
+	IsBinary bool
+}
+
+// IsRenamed returns true if the file has been renamed.
+func (f *DiffStatFile) IsRenamed() bool {
+	return f.OldPath != ""
+}
+
+// DiffStat contains the line statistics of files in diff.
+type DiffStat struct {
+	Files []*DiffStatFile // The files in the diff
+
+	totalAdditions int
+	totalDeletions int
+}
+
+// NumFiles returns the number of files in the diff.
+func (s *DiffStat) NumFiles() int {
+	return len(s.Files)
+}
+
+// TotalAdditions returns the total additions in the diff.
+func (s *DiffStat) TotalAdditions() int {
+	return s.totalAdditions
+}
+
+// TotalDeletions returns the total deletions in the diff.
+func (s *DiffStat) TotalDeletions() int {
+	return s.totalDeletions
+}
+
+// parseDiffStat parses the output of "git diff --numstat -z", e.g.
+//
+//	1\t2\tREADME.txt\x00
+//	-\t-\timage.png\x00
+//	3\t0\t\x00old.txt\x00new.txt\x00
+func parseDiffStat(data []byte) (*DiffStat, error) {
+	stat := &DiffStat{
+		Files: []*DiffStatFile{},
+	}
+
+	fields := bytes.Split(data, []byte{0})
+	for i := 0; i < len(fields); i++ {
+		if len(fields[i]) == 0 {
+			continue
+		}
+
+		parts := bytes.SplitN(fields[i], []byte{'\t'}, 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("malformed numstat: %q", fields[i])
+		}
+
+		file := new(DiffStatFile)
+		if string(parts[0]) == "-" && string(parts[1]) == "-" {
+			file.IsBinary = true
+		} else {
+			var err error
+			file.Additions, err = strconv.Atoi(string(parts[0]))
+			if err != nil {
+				return nil, fmt.Errorf("parse additions: %v", err)
+			}
+			file.Deletions, err = strconv.Atoi(string(parts[1]))
+			if err != nil {
+				return nil, fmt.Errorf("parse deletions: %v", err)
+			}
+		}
+
+		// An empty path indicates a rename, followed by the old and new paths.
+		if len(parts[2]) == 0 {
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("malformed numstat: missing paths for %q", fields[i])
+			}
+			file.OldPath = string(fields[i+1])
+			file.Path = string(fields[i+2])
+			i += 2
+		} else {
+			file.Path = string(parts[2])
+		}
+
+		stat.Files = append(stat.Files, file)
+		stat.totalAdditions += file.Additions
+		stat.totalDeletions += file.Deletions
+	}
+	return stat, nil
+}
+
+// DiffStatOptions contains optional arguments for computing line statistics of
+// a diff.
+//
+// Docs: https://git-scm.com/docs/git-diff#Documentation/git-diff.txt---numstat
+type DiffStatOptions struct {
+	// Indicates whether two commits should have a merge base.
+	NeedsMergeBase bool
+	// The relative path of the repository.
+	Path string
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// DiffStat returns the line statistics of changed files between base and head
+// revisions of the repository.
+func (r *Repository) DiffStat(base, head string, opts ...DiffStatOptions) (*DiffStat, error) {
+	var opt DiffStatOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("diff").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--numstat", "-z", "-M", "--end-of-options")
+	if opt.NeedsMergeBase {
+		cmd.AddArgs(base + "..." + head)
+	} else {
+		cmd.AddArgs(base, head)
+	}
+	cmd.AddArgs("--")
+	if opt.Path != "" {
+		cmd.AddArgs(escapePath(opt.Path))
+	}
+
+	stdout, err := cmd.RunInDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseDiffStat(stdout)
+}
+
+// parseRawDiff parses the output of "git diff --raw --numstat -z", which lists
+// all raw entries before the line statistics, e.g.
+//
+//	:100644 100644 <old index> <index> M\x00README.txt\x00
+//	:100644 100644 <old index> <index> R086\x00old.txt\x00new.txt\x00
+//	1\t2\tREADME.txt\x00
+//	3\t0\t\x00old.txt\x00new.txt\x00
+func parseRawDiff(data []byte) (*Diff, error) {
+	diff := &Diff{
+		Files: []*DiffFile{},
+	}
+
+	fields := bytes.Split(data, []byte{0})
+	i := 0
+	for ; i < len(fields); i++ {
+		if len(fields[i]) == 0 || fields[i][0] != ':' {
+			break
+		}
+
+		meta := strings.Fields(string(fields[i][1:]))
+		if len(meta) != 5 || meta[4] == "" {
+			return nil, fmt.Errorf("malformed raw diff: %q", fields[i])
+		}
+		oldMode, err := strconv.ParseUint(meta[0], 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("parse old mode: %v", err)
+		}
+		mode, err := strconv.ParseUint(meta[1], 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("parse mode: %v", err)
+		}
+
+		file := &DiffFile{
+			Type:     DiffFileChange,
+			OldIndex: meta[2],
+			Index:    meta[3],
+			mode:     EntryMode(mode),
+			oldMode:  EntryMode(oldMode),
+		}
+		status := meta[4]
+		switch status[0] {
+		case 'A':
+			file.Type = DiffFileAdd
+			file.oldMode = file.mode
+		case 'D':
+			file.Type = DiffFileDelete
+			file.mode = file.oldMode
+		case 'R':
+			file.Type = DiffFileRename
+		case 'C':
+			file.Type = DiffFileCopy
+		}
+		file.isSubmodule = file.mode == EntryCommit
+
+		if file.Type == DiffFileRename || file.Type == DiffFileCopy {
+			if i+2 >= len(fields) {
+				return nil, fmt.Errorf("malformed raw diff: missing paths for %q", fields[i])
+			}
+			file.similarity, _ = strconv.Atoi(status[1:])
+			file.oldName = string(fields[i+1])
+			file.Name = string(fields[i+2])
+			i += 2
+		} else {
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("malformed raw diff: missing path for %q", fields[i])
+			}
+			file.Name = string(fields[i+1])
+			file.oldName = file.Name
+			i++
+		}
+		diff.Files = append(diff.Files, file)
+	}
+
+	stat, err := parseDiffStat(bytes.Join(fields[i:], []byte{0}))
+	if err != nil {
+		return nil, err
+	}
+	if len(stat.Files) != len(diff.Files) {
+		return nil, fmt.Errorf("malformed raw diff: %d entries but %d line statistics", len(diff.Files), len(stat.Files))
+	}
+	for i, file := range diff.Files {
+		file.numAdditions = stat.Files[i].Additions
+		file.numDeletions = stat.Files[i].Deletions
+		file.isBinary = stat.Files[i].IsBinary
+	}
+	diff.totalAdditions = stat.totalAdditions
+	diff.totalDeletions = stat.totalDeletions
+	return diff, nil
+}
+
+// DiffFilesOptions contains optional arguments for listing files of a diff.
+//
+// Docs: https://git-scm.com/docs/git-diff#_raw_output_format
+type DiffFilesOptions struct {
+	// Indicates whether two commits should have a merge base.
+	NeedsMergeBase bool
+	// The similarity threshold (0-100) in percentage to detect renames. Git's
+	// default (50) is used when not set.
+	RenameThreshold int
+	// Indicates whether to detect copies in addition to renames.
+	DetectCopies bool
+	// The specific pathspecs to limit the diff to.
+	Pathspecs []string
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// DiffFiles returns the changed files between base and head revisions of the
+// repository with their types, modes, indexes and line statistics, but without
+// sections. It is much cheaper than Diff for large changes, and sections of
+// each file can be loaded on demand by DiffFile.
+func (r *Repository) DiffFiles(base, head string, opts ...DiffFilesOptions) (*Diff, error) {
+	var opt DiffFilesOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	diffOpt := DiffOptions{
+		RenameThreshold: opt.RenameThreshold,
+		DetectCopies:    opt.DetectCopies,
+	}
+	cmd := NewCommand("diff").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--raw", "--numstat", "-z", "--no-abbrev").
+		AddArgs(diffOpt.args()...).
+		AddArgs("--end-of-options")
+	if opt.NeedsMergeBase {
+		cmd.AddArgs(base + "..." + head)
+	} else {
+		cmd.AddArgs(base, head)
+	}
+	cmd.AddArgs("--")
+	cmd.AddArgs(opt.Pathspecs...)
+
+	stdout, err := cmd.RunInDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseRawDiff(stdout)
+}
+
+// DiffFileOptions contains optional arguments for loading a file of a diff.
+//
+// Docs: https://git-scm.com/docs/git-diff
+type DiffFileOptions struct {
+	// Indicates whether two commits should have a merge base.
+	NeedsMergeBase bool
+	// The path of the file before renaming or copying, i.e. DiffFile.OldName.
+	// It is required to load a renamed or copied file as such, otherwise the file
+	// is loaded as newly created.
+	OldPath string
+	// The number of context lines around changes, which can be increased to show
+	// more lines. Git's default (3) is used when not set, and no context line is
+	// shown when negative.
+	ContextLines int
+	// The maximum number of lines to be parsed, the file is marked as incomplete
+	// when exceeded. No limit when not set.
+	MaxLines int
+	// The maximum number of characters of a line to be parsed. No limit when not
+	// set.
+	MaxLineChars int
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// DiffFile returns the diff of the file in given path between base and head
+// revisions of the repository. It returns ErrDiffFileNotExist when the file is
+// not changed.
+func (r *Repository) DiffFile(base, head, path string, opts ...DiffFileOptions) (*DiffFile, error) {
+	var opt DiffFileOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	diffOpt := DiffOptions{
+		ContextLines: opt.ContextLines,
+	}
+	cmd := NewCommand("diff").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--full-index").
+		AddArgs(diffOpt.args()...)
+	if opt.OldPath != "" {
+		// The source of a copy is not necessarily changed
+		cmd.AddArgs("-C", "--find-copies-harder")
+	}
+	cmd.AddArgs("--end-of-options")
+	if opt.NeedsMergeBase {
+		cmd.AddArgs(base + "..." + head)
+	} else {
+		cmd.AddArgs(base, head)
+	}
+	cmd.AddArgs("--", escapePath(path))
+	if opt.OldPath != "" {
+		cmd.AddArgs(escapePath(opt.OldPath))
+	}
+
+	stdout, w := io.Pipe()
+	// The result is not received when the command fails, buffer it to let the
+	// parsing goroutine exit.
+	done := make(chan SteamParseDiffResult, 1)
+	go StreamParseDiff(stdout, done, 0, opt.MaxLines, opt.MaxLineChars)
+
+	stderr := new(bytes.Buffer)
+	err := cmd.RunInDirPipeline(w, stderr, r.path)
+	_ = w.Close() // Close writer to exit parsing goroutine
+	if err != nil {
+		return nil, concatenateError(err, stderr.String())
+	}
+
+	result := <-done
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	for _, file := range result.Diff.Files {
+		if file.Name == path {
+			return file, nil
+		}
+	}
+	return nil, ErrDiffFileNotExist
+}
+
+// ExpandDiffSectionOptions contains optional arguments for expanding a section
+// of a diff file.
+type ExpandDiffSectionOptions struct {
+	// The number of context lines to add above the section.
+	Above int
+	// The number of context lines to add below the section.
+	Below int
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// contextLines returns plain lines from given range [from, to] of the blob
+// lines, the left line numbers are shifted by given offset.
+func contextLines(lines []string, from, to, offset int) ([]*DiffLine, error) {
+	if to > len(lines) {
+		return nil, fmt.Errorf("line %d is out of range of the blob that has %d lines", to, len(lines))
+	}
+
+	diffLines := make([]*DiffLine, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		diffLines = append(diffLines, &DiffLine{
+			Type:      DiffLinePlain,
+			Content:   " " + lines[n-1],
+			LeftLine:  n + offset,
+			RightLine: n,
+		})
+	}
+	return diffLines, nil
+}
+
+// ExpandDiffSection adds context lines above and below the section of the file
+// from the blob of the file, and returns the expanded section. The section is
+// merged with its adjacent section when the gap between them is closed. The
+// sections of the file are updated in place, and the given section should not
+// be used afterwards.
+func (r *Repository) ExpandDiffSection(file *DiffFile, section *DiffSection, opts ...ExpandDiffSectionOptions) (*DiffSection, error) {
+	var opt ExpandDiffSectionOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if file.isCombined {
+		return nil, errors.New("expanding sections of combined diff is not supported")
+	}
+	idx := -1
+	for i := range file.Sections {
+		if file.Sections[i] == section {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, errors.New("the section does not belong to the file")
+	}
+
+	// A deleted file has nothing outside of its only section.
+	if (opt.Above <= 0 && opt.Below <= 0) || file.IsDeleted() {
+		return section, nil
+	}
+
+	leftFirst, leftCount, rightFirst, rightCount, suffix, err := section.hunkRange()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := NewCommand("cat-file", "blob").
+		AddOptions(opt.CommandOptions).
+		AddArgs(file.Index).
+		RunInDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	if len(data) > 0 {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	}
+
+	var (
+		prev, next *DiffSection
+		above      []*DiffLine
+		below      []*DiffLine
+	)
+	if opt.Above > 0 {
+		gapStart := 1
+		if idx > 0 {
+			_, _, prevFirst, prevCount, _, err := file.Sections[idx-1].hunkRange()
+			if err != nil {
+				return nil, err
+			}
+			gapStart = prevFirst + prevCount
+		}
+
+		from := rightFirst - opt.Above
+		if from <= gapStart {
+			from = gapStart
+			if idx > 0 {
+				prev = file.Sections[idx-1]
+			}
+		}
+		above, err = contextLines(lines, from, rightFirst-1, leftFirst-rightFirst)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if opt.Below > 0 {
+		gapEnd := len(lines)
+		if idx < len(file.Sections)-1 {
+			_, _, nextFirst, _, _, err := file.Sections[idx+1].hunkRange()
+			if err != nil {
+				return nil, err
+			}
+			gapEnd = nextFirst - 1
+		}
+
+		rightNext := rightFirst + rightCount
+		to := rightNext + opt.Below - 1
+		if to >= gapEnd {
+			to = gapEnd
+			if idx < len(file.Sections)-1 {
+				next = file.Sections[idx+1]
+			}
+		}
+		below, err = contextLines(lines, rightNext, to, leftFirst+leftCount-rightNext)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(above) > 0 {
+		leftFirst = above[0].LeftLine
+		rightFirst = above[0].RightLine
+		// The text after the header (e.g. the function name) is for the original
+		// first line and might be inaccurate for the new one.
+		suffix = ""
+	}
+	expanded := &DiffSection{
+		Lines: []*DiffLine{{Type: DiffLineSection}},
+	}
+	start, end := idx, idx
+	if prev != nil {
+		start--
+		leftFirst, _, rightFirst, _, suffix, _ = prev.hunkRange()
+		expanded.Lines = append(expanded.Lines, prev.Lines[1:]...)
+		expanded.numAdditions += prev.numAdditions
+		expanded.numDeletions += prev.numDeletions
+	}
+	expanded.Lines = append(expanded.Lines, above...)
+	expanded.Lines = append(expanded.Lines, section.Lines[1:]...)
+	expanded.Lines = append(expanded.Lines, below...)
+	expanded.numAdditions += section.numAdditions
+	expanded.numDeletions += section.numDeletions
+	if next != nil {
+		end++
+		expanded.Lines = append(expanded.Lines, next.Lines[1:]...)
+		expanded.numAdditions += next.numAdditions
+		expanded.numDeletions += next.numDeletions
+	}
+
+	leftCount, rightCount = 0, 0
+	for _, line := range expanded.Lines[1:] {
+		switch line.Type {
+		case DiffLinePlain:
+			leftCount++
+			rightCount++
+		case DiffLineDelete:
+			leftCount++
+		case DiffLineAdd:
+			rightCount++
+		}
+	}
+	expanded.Lines[0].Content = formatHunkHeader(leftFirst, leftCount, rightFirst, rightCount, suffix)
+
+	sections := make([]*DiffSection, 0, len(file.Sections)-(end-start))
+	sections = append(sections, file.Sections[:start]...)
+	sections = append(sections, expanded)
+	sections = append(sections, file.Sections[end+1:]...)
+	file.Sections = sections
+	return expanded, nil
+}
+
 // RawDiffFormat is the format of a raw diff.
 type RawDiffFormat string
 
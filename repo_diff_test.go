@@ -7,10 +7,14 @@ package git
 import (
 	"bytes"
 	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRepository_Diff(t *testing.T) {
@@ -299,6 +303,511 @@ func TestRepository_Diff(t *testing.T) {
 	}
 }
 
+func TestRepository_Diff_Combined(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	// Commit both sides and the resolution in a row, then merge the sides with
+	// the tree of the resolution.
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	commitFile := func(content string) *Commit {
+		err := os.WriteFile(filepath.Join(r.Path(), "merge.txt"), []byte(content), 0600)
+		require.NoError(t, err)
+		require.NoError(t, r.Add(AddOptions{All: true}))
+		require.NoError(t, r.Commit(alice, "Update merge.txt"))
+		c, err := r.CatFileCommit("HEAD")
+		require.NoError(t, err)
+		return c
+	}
+	ours := commitFile("a\nB master\nc\n")
+	theirs := commitFile("a\nB side\nc\n")
+	resolved := commitFile("a\nB merged\nc\nd\n")
+
+	merge, err := r.CommitTree(resolved.Tree.id.String(), "Merge", CommitTreeOptions{
+		Parents:   []string{ours.ID.String(), theirs.ID.String()},
+		Committer: alice,
+	})
+	require.NoError(t, err)
+
+	diff, err := r.Diff(merge.String(), 0, 0, 0, DiffOptions{Combined: true})
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 1)
+
+	f := diff.Files[0]
+	assert.Equal(t, "merge.txt", f.Name)
+	assert.True(t, f.IsCombined())
+	assert.Equal(t, 2, f.NumParents())
+	assert.Equal(t, []string{
+		"80315cfa353254b99c34b0b4da3dd7c880f63641",
+		"53eec95e02f369d6187bd0be18f7ca45b2b26496",
+	}, f.ParentIndexes)
+	assert.Equal(t, "f1886d7a9bebaeaa003091a293a851f366e30bd8", f.Index)
+	assert.Equal(t, 2, f.NumAdditions())
+	assert.Equal(t, 2, f.NumDeletions())
+	require.Len(t, f.Sections, 1)
+	assert.Equal(t, "@@@ -1,3 -1,3 +1,4 @@@", f.Sections[0].Lines[0].Content)
+
+	// Without the option, the diff is against the first parent
+	diff, err = r.Diff(merge.String(), 0, 0, 0)
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 1)
+	assert.False(t, diff.Files[0].IsCombined())
+	assert.Equal(t, "80315cfa353254b99c34b0b4da3dd7c880f63641", diff.Files[0].OldIndex)
+}
+
+func TestDiffOptions_args(t *testing.T) {
+	tests := []struct {
+		opt     DiffOptions
+		expArgs []string
+	}{
+		{
+			opt:     DiffOptions{},
+			expArgs: []string{"-M"},
+		},
+		{
+			opt: DiffOptions{
+				RenameThreshold:  90,
+				DetectCopies:     true,
+				IgnoreAllSpace:   true,
+				IgnoreBlankLines: true,
+				Algorithm:        DiffAlgorithmHistogram,
+				ContextLines:     5,
+				FunctionContext:  true,
+			},
+			expArgs: []string{"-M90%", "-C", "--ignore-all-space", "--ignore-blank-lines", "--diff-algorithm=histogram", "--unified=5", "--function-context"},
+		},
+		{
+			opt: DiffOptions{
+				CopyThreshold: 75,
+				ContextLines:  -1,
+			},
+			expArgs: []string{"-M", "-C75%", "--unified=0"},
+		},
+	}
+	for _, test := range tests {
+		t.Run("", func(t *testing.T) {
+			assert.Equal(t, test.expArgs, test.opt.args())
+		})
+	}
+}
+
+func TestRepository_Diff_Options(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	writeFile := func(name, content string) {
+		err := os.WriteFile(filepath.Join(r.Path(), name), []byte(content), 0600)
+		require.NoError(t, err)
+	}
+
+	original := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	writeFile("original.txt", original)
+	writeFile("other.txt", "a\nb\n")
+	require.NoError(t, r.Add(AddOptions{All: true}))
+	require.NoError(t, r.Commit(alice, "Add files"))
+
+	// Copy the file while modifying the original, and change only whitespace of
+	// the other file.
+	writeFile("copied.txt", original)
+	writeFile("original.txt", strings.Replace(original, "5\n", "five\n", 1))
+	writeFile("other.txt", "a \nb\n")
+	require.NoError(t, r.Add(AddOptions{All: true}))
+	require.NoError(t, r.Commit(alice, "Copy file"))
+
+	t.Run("detect copies", func(t *testing.T) {
+		diff, err := r.Diff("HEAD", 0, 0, 0, DiffOptions{DetectCopies: true})
+		require.NoError(t, err)
+		require.Len(t, diff.Files, 3)
+
+		f := diff.Files[0]
+		assert.Equal(t, "copied.txt", f.Name)
+		assert.Equal(t, DiffFileCopy, f.Type)
+		assert.Equal(t, "original.txt", f.OldName())
+
+		diff, err = r.Diff("HEAD", 0, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, diff.Files, 3)
+		assert.Equal(t, DiffFileAdd, diff.Files[0].Type)
+	})
+
+	t.Run("ignore whitespace and limit paths", func(t *testing.T) {
+		diff, err := r.Diff("HEAD", 0, 0, 0, DiffOptions{
+			IgnoreAllSpace: true,
+			Pathspecs:      []string{"other.txt", "original.txt"},
+		})
+		require.NoError(t, err)
+
+		// The file with only whitespace changes is omitted
+		require.Len(t, diff.Files, 1)
+		assert.Equal(t, "original.txt", diff.Files[0].Name)
+	})
+
+	t.Run("context lines", func(t *testing.T) {
+		diff, err := r.Diff("HEAD", 0, 0, 0, DiffOptions{
+			ContextLines: -1,
+			Pathspecs:    []string{"original.txt"},
+		})
+		require.NoError(t, err)
+		require.Len(t, diff.Files, 1)
+		require.Len(t, diff.Files[0].Sections, 1)
+		assert.Equal(t, 3, diff.Files[0].Sections[0].NumLines())
+
+		diff, err = r.Diff("HEAD", 0, 0, 0, DiffOptions{
+			ContextLines: 1,
+			Algorithm:    DiffAlgorithmPatience,
+			Pathspecs:    []string{"original.txt"},
+		})
+		require.NoError(t, err)
+		require.Len(t, diff.Files, 1)
+		assert.Equal(t, 5, diff.Files[0].Sections[0].NumLines())
+	})
+}
+
+func Test_parseDiffStat(t *testing.T) {
+	input := "1\t2\tREADME.txt\x00-\t-\timage.png\x00" +
+		"3\t0\t\x00old name.txt\x00new name.txt\x00"
+	stat, err := parseDiffStat([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &DiffStat{
+		Files: []*DiffStatFile{
+			{
+				Path:      "README.txt",
+				Additions: 1,
+				Deletions: 2,
+			},
+			{
+				Path:     "image.png",
+				IsBinary: true,
+			},
+			{
+				Path:      "new name.txt",
+				OldPath:   "old name.txt",
+				Additions: 3,
+			},
+		},
+		totalAdditions: 4,
+		totalDeletions: 2,
+	}
+	assert.Equal(t, want, stat)
+	assert.Equal(t, 3, stat.NumFiles())
+	assert.False(t, stat.Files[0].IsRenamed())
+	assert.True(t, stat.Files[2].IsRenamed())
+
+	_, err = parseDiffStat([]byte("1\t2\x00"))
+	assert.Error(t, err)
+}
+
+func TestRepository_DiffStat(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	err = os.WriteFile(filepath.Join(r.Path(), "stat.txt"), []byte("1\n2\n3\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(r.Path(), "stat.bin"), []byte{0, 1, 2}, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Add(AddOptions{All: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err = r.Commit(&Signature{Name: "alice", Email: "alice@example.com"}, "Add files"); err != nil {
+		t.Fatal(err)
+	}
+
+	stat, err := r.DiffStat("HEAD~1", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &DiffStat{
+		Files: []*DiffStatFile{
+			{
+				Path:     "stat.bin",
+				IsBinary: true,
+			},
+			{
+				Path:      "stat.txt",
+				Additions: 3,
+			},
+		},
+		totalAdditions: 3,
+	}
+	assert.Equal(t, want, stat)
+
+	t.Run("limit to path", func(t *testing.T) {
+		stat, err := r.DiffStat("HEAD~1", "HEAD", DiffStatOptions{
+			Path: "stat.txt",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, 1, stat.NumFiles())
+		assert.Equal(t, 3, stat.TotalAdditions())
+	})
+
+	t.Run("bad revision", func(t *testing.T) {
+		_, err := r.DiffStat("404", "HEAD")
+		assert.Error(t, err)
+	})
+}
+
+func Test_parseRawDiff(t *testing.T) {
+	input := ":100644 100755 83db48f84ec878fbfb30b46d16630e944e34f205 a16f0f76662d4657cc5c241dcaa9684018cb7dfa M\x00README.txt\x00" +
+		":000000 100644 0000000000000000000000000000000000000000 83fdd150815ff2a903f9ec587cc26c17ae43af87 A\x00bin.dat\x00" +
+		":100644 100644 e69de29bb2d1d6434b8b29ae775ad8c2e48c5391 e69de29bb2d1d6434b8b29ae775ad8c2e48c5391 R100\x00old.txt\x00new.txt\x00" +
+		":160000 000000 978fb7f6388b49b532fbef8b856681cfa6fcaa0a 0000000000000000000000000000000000000000 D\x00sub\x00" +
+		"2\t1\tREADME.txt\x00" +
+		"-\t-\tbin.dat\x00" +
+		"0\t0\t\x00old.txt\x00new.txt\x00" +
+		"0\t1\tsub\x00"
+
+	diff, err := parseRawDiff([]byte(input))
+	require.NoError(t, err)
+	assert.Equal(t, 2, diff.TotalAdditions())
+	assert.Equal(t, 2, diff.TotalDeletions())
+	assert.Equal(t, []*DiffFile{
+		{
+			Name:         "README.txt",
+			Type:         DiffFileChange,
+			Index:        "a16f0f76662d4657cc5c241dcaa9684018cb7dfa",
+			OldIndex:     "83db48f84ec878fbfb30b46d16630e944e34f205",
+			numAdditions: 2,
+			numDeletions: 1,
+			oldName:      "README.txt",
+			mode:         EntryExec,
+			oldMode:      EntryBlob,
+		},
+		{
+			Name:     "bin.dat",
+			Type:     DiffFileAdd,
+			Index:    "83fdd150815ff2a903f9ec587cc26c17ae43af87",
+			OldIndex: "0000000000000000000000000000000000000000",
+			oldName:  "bin.dat",
+			mode:     EntryBlob,
+			oldMode:  EntryBlob,
+			isBinary: true,
+		},
+		{
+			Name:       "new.txt",
+			Type:       DiffFileRename,
+			Index:      "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391",
+			OldIndex:   "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391",
+			oldName:    "old.txt",
+			similarity: 100,
+			mode:       EntryBlob,
+			oldMode:    EntryBlob,
+		},
+		{
+			Name:         "sub",
+			Type:         DiffFileDelete,
+			Index:        "0000000000000000000000000000000000000000",
+			OldIndex:     "978fb7f6388b49b532fbef8b856681cfa6fcaa0a",
+			numDeletions: 1,
+			oldName:      "sub",
+			mode:         EntryCommit,
+			oldMode:      EntryCommit,
+			isSubmodule:  true,
+		},
+	}, diff.Files)
+
+	t.Run("empty", func(t *testing.T) {
+		diff, err := parseRawDiff(nil)
+		require.NoError(t, err)
+		assert.Empty(t, diff.Files)
+	})
+
+	t.Run("mismatched statistics", func(t *testing.T) {
+		_, err := parseRawDiff([]byte(":100644 100644 83db48f84ec878fbfb30b46d16630e944e34f205 a16f0f76662d4657cc5c241dcaa9684018cb7dfa M\x00README.txt\x00"))
+		assert.Error(t, err)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := parseRawDiff([]byte(":100644 M\x00README.txt\x00"))
+		assert.Error(t, err)
+	})
+}
+
+func TestRepository_DiffFiles(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	writeFile := func(name, content string) {
+		err := os.WriteFile(filepath.Join(r.Path(), name), []byte(content), 0600)
+		require.NoError(t, err)
+	}
+
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, strconv.Itoa(i))
+	}
+	writeFile("lines.txt", strings.Join(lines, "\n")+"\n")
+	writeFile("moved.txt", strings.Join(lines, " ")+"\n")
+	require.NoError(t, r.Add(AddOptions{All: true}))
+	require.NoError(t, r.Commit(alice, "Add files"))
+
+	lines[9] = "ten"
+	writeFile("lines.txt", strings.Join(lines, "\n")+"\n")
+	require.NoError(t, os.Rename(filepath.Join(r.Path(), "moved.txt"), filepath.Join(r.Path(), "renamed.txt")))
+	writeFile("image.bin", "\x00\x01\x02")
+	require.NoError(t, r.Add(AddOptions{All: true}))
+	require.NoError(t, r.Commit(alice, "Change files"))
+
+	diff, err := r.DiffFiles("HEAD~1", "HEAD")
+	require.NoError(t, err)
+	require.Len(t, diff.Files, 3)
+	assert.Equal(t, 1, diff.TotalAdditions())
+	assert.Equal(t, 1, diff.TotalDeletions())
+
+	assert.Equal(t, "image.bin", diff.Files[0].Name)
+	assert.Equal(t, DiffFileAdd, diff.Files[0].Type)
+	assert.True(t, diff.Files[0].IsBinary())
+
+	assert.Equal(t, "lines.txt", diff.Files[1].Name)
+	assert.Equal(t, DiffFileChange, diff.Files[1].Type)
+	assert.Len(t, diff.Files[1].Index, 40)
+	assert.Equal(t, 1, diff.Files[1].NumAdditions())
+	assert.Empty(t, diff.Files[1].Sections)
+
+	assert.Equal(t, "renamed.txt", diff.Files[2].Name)
+	assert.Equal(t, "moved.txt", diff.Files[2].OldName())
+	assert.True(t, diff.Files[2].IsRenamed())
+
+	t.Run("load a file", func(t *testing.T) {
+		f, err := r.DiffFile("HEAD~1", "HEAD", "lines.txt")
+		require.NoError(t, err)
+		require.Len(t, f.Sections, 1)
+		assert.Equal(t, diff.Files[1].Index, f.Index)
+		assert.Equal(t, "@@ -7,7 +7,7 @@", f.Sections[0].Lines[0].Content)
+
+		// Show more lines around the change
+		f, err = r.DiffFile("HEAD~1", "HEAD", "lines.txt", DiffFileOptions{ContextLines: 5})
+		require.NoError(t, err)
+		require.Len(t, f.Sections, 1)
+		assert.Equal(t, "@@ -5,11 +5,11 @@", f.Sections[0].Lines[0].Content)
+	})
+
+	t.Run("load a renamed file", func(t *testing.T) {
+		f, err := r.DiffFile("HEAD~1", "HEAD", "renamed.txt", DiffFileOptions{OldPath: "moved.txt"})
+		require.NoError(t, err)
+		assert.True(t, f.IsRenamed())
+		assert.Equal(t, "moved.txt", f.OldName())
+
+		f, err = r.DiffFile("HEAD~1", "HEAD", "renamed.txt")
+		require.NoError(t, err)
+		assert.True(t, f.IsCreated())
+	})
+
+	t.Run("file not changed", func(t *testing.T) {
+		_, err := r.DiffFile("HEAD~1", "HEAD", "404.txt")
+		assert.Equal(t, ErrDiffFileNotExist, err)
+	})
+}
+
+func TestRepository_ExpandDiffSection(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	writeFile := func(lines []string) {
+		err := os.WriteFile(filepath.Join(r.Path(), "expand.txt"), []byte(strings.Join(lines, "\n")+"\n"), 0600)
+		require.NoError(t, err)
+		require.NoError(t, r.Add(AddOptions{All: true}))
+		require.NoError(t, r.Commit(alice, "Update expand.txt"))
+	}
+
+	var lines []string
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, "line "+strconv.Itoa(i))
+	}
+	writeFile(lines)
+
+	// Change line 5 with an extra line, and delete line 20, so line numbers of
+	// both sides differ.
+	changed := append([]string{}, lines[:4]...)
+	changed = append(changed, "line five", "line 5.5")
+	changed = append(changed, lines[5:19]...)
+	changed = append(changed, lines[20:]...)
+	writeFile(changed)
+
+	loadFile := func() *DiffFile {
+		f, err := r.DiffFile("HEAD~1", "HEAD", "expand.txt")
+		require.NoError(t, err)
+		require.Len(t, f.Sections, 2)
+		assert.Equal(t, "@@ -2,7 +2,8 @@ line 1", f.Sections[0].Lines[0].Content)
+		assert.Equal(t, "@@ -17,7 +18,6 @@ line 16", f.Sections[1].Lines[0].Content)
+		return f
+	}
+
+	t.Run("expand without closing the gap", func(t *testing.T) {
+		f := loadFile()
+		section, err := r.ExpandDiffSection(f, f.Sections[1], ExpandDiffSectionOptions{Above: 3, Below: 2})
+		require.NoError(t, err)
+		require.Len(t, f.Sections, 2)
+		assert.Equal(t, section, f.Sections[1])
+		assert.Equal(t, "@@ -14,12 +15,11 @@", section.Lines[0].Content)
+		assert.Equal(t, &DiffLine{Type: DiffLinePlain, Content: " line 14", LeftLine: 14, RightLine: 15}, section.Lines[1])
+		assert.Equal(t, &DiffLine{Type: DiffLinePlain, Content: " line 25", LeftLine: 25, RightLine: 25}, section.Lines[len(section.Lines)-1])
+		assert.Equal(t, 1, section.numDeletions)
+	})
+
+	t.Run("merge sections", func(t *testing.T) {
+		f := loadFile()
+		section, err := r.ExpandDiffSection(f, f.Sections[1], ExpandDiffSectionOptions{Above: 10})
+		require.NoError(t, err)
+		require.Len(t, f.Sections, 1)
+		assert.Equal(t, "@@ -2,22 +2,22 @@ line 1", section.Lines[0].Content)
+		assert.Equal(t, 2, section.numAdditions)
+		assert.Equal(t, 2, section.numDeletions)
+
+		// Expand to both ends of the file, which must be the same as the diff
+		// with the whole file as context.
+		section, err = r.ExpandDiffSection(f, section, ExpandDiffSectionOptions{Above: 10, Below: 10})
+		require.NoError(t, err)
+		require.Len(t, f.Sections, 1)
+
+		full, err := r.DiffFile("HEAD~1", "HEAD", "expand.txt", DiffFileOptions{ContextLines: 100})
+		require.NoError(t, err)
+		require.Len(t, full.Sections, 1)
+		assert.Equal(t, full.Sections[0], section)
+	})
+
+	t.Run("nothing to expand", func(t *testing.T) {
+		f := loadFile()
+		section := f.Sections[0]
+		expanded, err := r.ExpandDiffSection(f, section)
+		require.NoError(t, err)
+		assert.Equal(t, section, expanded)
+
+		expanded, err = r.ExpandDiffSection(f, section, ExpandDiffSectionOptions{Above: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "@@ -1,8 +1,9 @@", expanded.Lines[0].Content)
+
+		// The beginning of the file has been reached
+		expanded, err = r.ExpandDiffSection(f, expanded, ExpandDiffSectionOptions{Above: 1})
+		require.NoError(t, err)
+		assert.Equal(t, "@@ -1,8 +1,9 @@", expanded.Lines[0].Content)
+	})
+
+	t.Run("section of another file", func(t *testing.T) {
+		f := loadFile()
+		_, err := r.ExpandDiffSection(f, new(DiffSection), ExpandDiffSectionOptions{Above: 1})
+		assert.Error(t, err)
+	})
+}
+
 func TestRepository_RawDiff(t *testing.T) {
 	t.Run("invalid revision", func(t *testing.T) {
 		err := testrepo.RawDiff("bad_revision", "bad_diff_type", nil)
@@ -5,26 +5,76 @@
 package git
 
 import (
+	"bufio"
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// GrepOperator is the operator to combine a pattern with the preceding ones.
+type GrepOperator string
+
+// A list of operators to combine patterns.
+const (
+	// GrepOr matches lines that match either side, which is the default.
+	GrepOr GrepOperator = "or"
+	// GrepAnd matches lines that match both sides. It binds tighter than GrepOr.
+	GrepAnd GrepOperator = "and"
+)
+
+// GrepPattern is an additional pattern of a grep search.
+type GrepPattern struct {
+	// The operator to combine the pattern with the preceding ones. Defaults to
+	// GrepOr.
+	Operator GrepOperator
+	// Indicates whether to match lines that do not match the pattern.
+	Not bool
+	// The pattern.
+	Pattern string
+}
+
 // GrepOptions contains optional arguments for grep search over repository files.
 //
 // Docs: https://git-scm.com/docs/git-grep
 type GrepOptions struct {
 	// The tree to run the search. Defaults to "HEAD".
 	Tree string
+	// The trees to run the search in one go, e.g. all branches, which takes
+	// precedence over Tree. Results are tagged by the tree they belong to.
+	Trees []string
 	// Limits the search to files in the specified pathspec.
 	Pathspec string
+	// The additional patterns combined with the pattern in order, e.g. the
+	// pattern "foo" with {Operator: GrepAnd, Not: true, Pattern: "bar"} matches
+	// lines that contain "foo" but not "bar".
+	Patterns []GrepPattern
 	// Whether to do case insensitive search.
 	IgnoreCase bool
 	// Whether to match the pattern only at word boundaries.
 	WordRegexp bool
 	// Whether use extended regular expressions.
 	ExtendedRegexp bool
+	// Whether to match patterns as fixed strings instead of regular expressions.
+	FixedStrings bool
+	// The number of lines to show before each match as context.
+	BeforeContext int
+	// The number of lines to show after each match as context.
+	AfterContext int
+	// The maximum number of matches in each file. It requires Git 2.38 or later.
+	MaxCountPerFile int
+	// The maximum number of results in total.
+	MaxResults int
+	// Whether to not match binary files.
+	SkipBinary bool
+	// Whether to match binary files as if they were text, which reports their
+	// matched lines instead of only their paths.
+	BinaryAsText bool
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -46,47 +96,176 @@ type GrepResult struct {
 	Column int
 	// The text of the line that matched.
 	Text string
+	// The lines before the match, only set with BeforeContext. It has fewer
+	// lines than requested at the start of the file.
+	Before []string
+	// The lines after the match, only set with AfterContext. It has fewer lines
+	// than requested at the end of the file.
+	After []string
+	// Indicates whether the file is binary, in which case only Tree and Path are
+	// set.
+	Binary bool
 }
 
-func parseGrepLine(line string) (*GrepResult, error) {
-	r := &GrepResult{}
-	sp := strings.SplitN(line, ":", 5)
-	var n int
-	switch len(sp) {
-	case 4:
-		// HEAD
-		r.Tree = "HEAD"
-	case 5:
-		// Tree included
-		r.Tree = sp[0]
-		n++
-	default:
-		return nil, fmt.Errorf("invalid grep line: %s", line)
-	}
-	r.Path = sp[n]
-	n++
-	r.Line, _ = strconv.Atoi(sp[n])
-	n++
-	r.Column, _ = strconv.Atoi(sp[n])
-	n++
-	r.Text = sp[n]
-	return r, nil
+// splitGrepName splits the name of a file in the output of "git grep" into the
+// tree and the path by the first of given trees that matches, e.g.
+// "HEAD:README.txt".
+func splitGrepName(name string, trees []string) (tree, path string) {
+	for _, tree := range trees {
+		if strings.HasPrefix(name, tree+":") {
+			return tree, name[len(tree)+1:]
+		}
+	}
+
+	i := strings.IndexByte(name, ':')
+	if i < 0 {
+		return "", name
+	}
+	return name[:i], name[i+1:]
 }
 
-// Grep returns the results of a grep search in the repository.
-func (r *Repository) Grep(pattern string, opts ...GrepOptions) []*GrepResult {
-	var opt GrepOptions
-	if len(opts) > 0 {
-		opt = opts[0]
+// grepLine is a line of the output of "git grep".
+type grepLine struct {
+	name string
+	line int
+	text string
+}
+
+// parseGrep parses the output of "git grep -z --line-number --column", and
+// calls fn with each result as soon as its context is complete, e.g.
+//
+//	Binary file HEAD:bin.dat matches
+//	HEAD:main.go\x001\x00package main
+//	HEAD:main.go\x002\x005\x00func main() {
+//	--
+//	HEAD:main.go\x0010\x005\x00func init() {
+//
+// where lines with a column are matches and the others are context.
+func parseGrep(r io.Reader, trees []string, before, after int, fn func(*GrepResult) error) error {
+	var (
+		name    string
+		recent  []*grepLine
+		pending []*GrepResult
+	)
+	flush := func() error {
+		for _, result := range pending {
+			if err := fn(result); err != nil {
+				return err
+			}
+		}
+		pending = pending[:0]
+		recent = recent[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+
+		// The separator between non-adjacent groups of lines
+		if line == "--" {
+			if err := flush(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		const binaryPrefix, binarySuffix = "Binary file ", " matches"
+		if strings.HasPrefix(line, binaryPrefix) && strings.HasSuffix(line, binarySuffix) {
+			if err := flush(); err != nil {
+				return err
+			}
+			result := &GrepResult{Binary: true}
+			result.Tree, result.Path = splitGrepName(line[len(binaryPrefix):len(line)-len(binarySuffix)], trees)
+			if err := fn(result); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fields := strings.SplitN(line, "\x00", 4)
+		if len(fields) < 3 {
+			return fmt.Errorf("invalid grep line: %q", line)
+		}
+		current := &grepLine{name: fields[0], text: fields[len(fields)-1]}
+		var err error
+		current.line, err = strconv.Atoi(fields[1])
+		if err != nil {
+			return fmt.Errorf("parse line number: %v", err)
+		}
+
+		if current.name != name {
+			if err = flush(); err != nil {
+				return err
+			}
+			name = current.name
+		}
+
+		// Complete the context of preceding matches
+		i := 0
+		for _, result := range pending {
+			if current.line <= result.Line+after {
+				result.After = append(result.After, current.text)
+			}
+			if current.line < result.Line+after {
+				pending[i] = result
+				i++
+			} else if err = fn(result); err != nil {
+				return err
+			}
+		}
+		pending = pending[:i]
+
+		// Lines with a column are matches
+		if len(fields) == 4 {
+			result := &GrepResult{
+				Line: current.line,
+				Text: current.text,
+			}
+			result.Tree, result.Path = splitGrepName(current.name, trees)
+			result.Column, err = strconv.Atoi(fields[2])
+			if err != nil {
+				return fmt.Errorf("parse column: %v", err)
+			}
+			for _, prev := range recent {
+				if prev.line >= current.line-before {
+					result.Before = append(result.Before, prev.text)
+				}
+			}
+
+			if after > 0 {
+				pending = append(pending, result)
+			} else if err = fn(result); err != nil {
+				return err
+			}
+		}
+
+		if before > 0 {
+			recent = append(recent, current)
+			if len(recent) > before {
+				recent = recent[1:]
+			}
+		}
 	}
-	if opt.Tree == "" {
-		opt.Tree = "HEAD"
+	if err := scanner.Err(); err != nil {
+		return err
 	}
+	return flush()
+}
+
+// errGrepStop is used to stop parsing results of a grep search.
+var errGrepStop = errors.New("stop")
 
+// grep runs "git grep" in given trees and calls fn with each result.
+func (r *Repository) grep(pattern string, trees []string, opt GrepOptions, fn func(*GrepResult) error) error {
 	cmd := NewCommand("grep").
 		AddOptions(opt.CommandOptions).
 		// Display full-name, line number and column number
-		AddArgs("--full-name", "--line-number", "--column")
+		AddArgs("-z", "--full-name", "--line-number", "--column")
 	if opt.IgnoreCase {
 		cmd.AddArgs("--ignore-case")
 	}
@@ -96,31 +275,139 @@ func (r *Repository) Grep(pattern string, opts ...GrepOptions) []*GrepResult {
 	if opt.ExtendedRegexp {
 		cmd.AddArgs("--extended-regexp")
 	}
-	cmd.AddArgs(
-		"--end-of-options",
-		pattern,
-		opt.Tree,
-	)
+	if opt.FixedStrings {
+		cmd.AddArgs("--fixed-strings")
+	}
+	if opt.BeforeContext > 0 {
+		cmd.AddArgs("--before-context=" + strconv.Itoa(opt.BeforeContext))
+	}
+	if opt.AfterContext > 0 {
+		cmd.AddArgs("--after-context=" + strconv.Itoa(opt.AfterContext))
+	}
+	if opt.MaxCountPerFile > 0 {
+		cmd.AddArgs("--max-count=" + strconv.Itoa(opt.MaxCountPerFile))
+	}
+	if opt.SkipBinary {
+		cmd.AddArgs("-I")
+	}
+	if opt.BinaryAsText {
+		cmd.AddArgs("--text")
+	}
+
+	cmd.AddArgs("-e", pattern)
+	for _, p := range opt.Patterns {
+		if p.Operator == GrepAnd {
+			cmd.AddArgs("--and")
+		} else {
+			cmd.AddArgs("--or")
+		}
+		if p.Not {
+			cmd.AddArgs("--not")
+		}
+		cmd.AddArgs("-e", p.Pattern)
+	}
+	// NOTE: "git grep" does not recognize "--end-of-options" after patterns,
+	// thus trees must be checked to not be treated as options.
+	for _, tree := range trees {
+		if strings.HasPrefix(tree, "-") {
+			return fmt.Errorf("invalid tree: %q", tree)
+		}
+	}
+	cmd.AddArgs(trees...)
+	cmd.AddArgs("--")
 	if opt.Pathspec != "" {
-		cmd.AddArgs("--", opt.Pathspec)
+		cmd.AddArgs(opt.Pathspec)
 	}
 
-	stdout, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
-	if err != nil {
+	// Match longer trees first in case one is a prefix of another, e.g. "main"
+	// and "main:docs".
+	names := make([]string, len(trees))
+	copy(names, trees)
+	sort.Slice(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j])
+	})
+
+	results := 0
+	stdout, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := parseGrep(stdout, names, opt.BeforeContext, opt.AfterContext, func(result *GrepResult) error {
+			if err := fn(result); err != nil {
+				return err
+			}
+			results++
+			if opt.MaxResults > 0 && results >= opt.MaxResults {
+				return errGrepStop
+			}
+			return nil
+		})
+		// Stop the command when parsing stops, otherwise drain the rest of output
+		// to not block the command.
+		if err != nil {
+			_ = stdout.CloseWithError(err)
+		} else {
+			_, _ = io.Copy(io.Discard, stdout)
+		}
+		done <- err
+	}()
+
+	stderr := new(bytes.Buffer)
+	err := cmd.RunInDirPipelineWithTimeout(opt.Timeout, w, stderr, r.path)
+	_ = w.Close() // Close writer to exit parsing goroutine
+	parseErr := <-done
+	if parseErr == errGrepStop {
 		return nil
+	} else if parseErr != nil {
+		return parseErr
 	}
 
-	var results []*GrepResult
-	// Normalize line endings
-	lines := strings.Split(strings.ReplaceAll(string(stdout), "\r", ""), "\n")
-	for _, line := range lines {
-		if len(line) == 0 {
-			continue
-		}
-		r, err := parseGrepLine(line)
-		if err == nil {
-			results = append(results, r)
+	if err != nil {
+		// Git exits with 1 when nothing matched
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stderr.Len() == 0 {
+			return nil
 		}
+		return concatenateError(err, stderr.String())
+	}
+	return nil
+}
+
+// grepTrees returns the trees to run the search by given options.
+func grepTrees(opt GrepOptions) []string {
+	if len(opt.Trees) > 0 {
+		return opt.Trees
+	}
+	if opt.Tree != "" {
+		return []string{opt.Tree}
+	}
+	return []string{"HEAD"}
+}
+
+// Grep returns the results of a grep search in the repository.
+func (r *Repository) Grep(pattern string, opts ...GrepOptions) ([]*GrepResult, error) {
+	var opt GrepOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	var results []*GrepResult
+	err := r.grep(pattern, grepTrees(opt), opt, func(result *GrepResult) error {
+		results = append(results, result)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// StreamGrep runs a grep search in the repository, and calls fn with each
+// result as soon as it is found, in the same order as Grep. It stops and
+// returns the error when fn returns one.
+func (r *Repository) StreamGrep(pattern string, fn func(*GrepResult) error, opts ...GrepOptions) error {
+	var opt GrepOptions
+	if len(opts) > 0 {
+		opt = opts[0]
 	}
-	return results
+	return r.grep(pattern, grepTrees(opt), opt, fn)
 }
@@ -5,10 +5,15 @@
 package git
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRepository_Grep_Simple(t *testing.T) {
@@ -51,7 +56,8 @@ func TestRepository_Grep_Simple(t *testing.T) {
 			Text:   `println "${programmingPoints} plus 3 bonus points is ${sum(programmingPoints, 3)}"`,
 		},
 	}
-	got := testrepo.Grep("programmingPoints")
+	got, err := testrepo.Grep("programmingPoints")
+	require.NoError(t, err)
 	assert.Equal(t, want, got)
 }
 
@@ -107,7 +113,8 @@ func TestRepository_Grep_IgnoreCase(t *testing.T) {
 			Text:   `        System.out.println( "Hello World!" );`,
 		},
 	}
-	got := testrepo.Grep("Hello", GrepOptions{IgnoreCase: true})
+	got, err := testrepo.Grep("Hello", GrepOptions{IgnoreCase: true})
+	require.NoError(t, err)
 	assert.Equal(t, want, got)
 }
 
@@ -125,7 +132,8 @@ func TestRepository_Grep_ExtendedRegexp(t *testing.T) {
 			Text:   `        System.out.println( "Hello World!" );`,
 		},
 	}
-	got := testrepo.Grep(`Hello\sW\w+`, GrepOptions{ExtendedRegexp: true})
+	got, err := testrepo.Grep(`Hello\sW\w+`, GrepOptions{ExtendedRegexp: true})
+	require.NoError(t, err)
 	assert.Equal(t, want, got)
 }
 
@@ -139,6 +147,219 @@ func TestRepository_Grep_WordRegexp(t *testing.T) {
 			Text:   ` * Hello world!`,
 		},
 	}
-	got := testrepo.Grep("world", GrepOptions{WordRegexp: true})
+	got, err := testrepo.Grep("world", GrepOptions{WordRegexp: true})
+	require.NoError(t, err)
 	assert.Equal(t, want, got)
 }
+
+func Test_parseGrep(t *testing.T) {
+	output := "Binary file HEAD:bin.dat matches\n" +
+		"HEAD:a.txt\x001\x001\n" +
+		"HEAD:a.txt\x002\x001\x00foo: bar\n" +
+		"HEAD:a.txt\x003\x003\n" +
+		"HEAD:a.txt\x004\x001\x00foo\r\n" +
+		"HEAD:a.txt\x005\x005\n" +
+		"--\n" +
+		"HEAD:a.txt\x009\x009\n" +
+		"HEAD:a.txt\x0010\x001\x00foo\n" +
+		"HEAD:sub:b.txt\x001\x003\x00a foo\n" +
+		"HEAD:sub:b.txt\x002\x002\n"
+
+	var results []*GrepResult
+	err := parseGrep(strings.NewReader(output), []string{"HEAD"}, 1, 1, func(result *GrepResult) error {
+		results = append(results, result)
+		return nil
+	})
+	require.NoError(t, err)
+
+	want := []*GrepResult{
+		{Tree: "HEAD", Path: "bin.dat", Binary: true},
+		{Tree: "HEAD", Path: "a.txt", Line: 2, Column: 1, Text: "foo: bar", Before: []string{"1"}, After: []string{"3"}},
+		{Tree: "HEAD", Path: "a.txt", Line: 4, Column: 1, Text: "foo", Before: []string{"3"}, After: []string{"5"}},
+		{Tree: "HEAD", Path: "a.txt", Line: 10, Column: 1, Text: "foo", Before: []string{"9"}},
+		{Tree: "HEAD", Path: "sub:b.txt", Line: 1, Column: 3, Text: "a foo", After: []string{"2"}},
+	}
+	assert.Equal(t, want, results)
+
+	t.Run("adjacent matches", func(t *testing.T) {
+		output := "HEAD:a.txt\x001\x001\x00foo\n" +
+			"HEAD:a.txt\x002\x001\x00foo\n" +
+			"HEAD:a.txt\x003\x003\n" +
+			"HEAD:a.txt\x004\x004\n"
+
+		var results []*GrepResult
+		err := parseGrep(strings.NewReader(output), []string{"HEAD"}, 2, 2, func(result *GrepResult) error {
+			results = append(results, result)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, []string{"foo", "3"}, results[0].After)
+		assert.Equal(t, []string{"foo"}, results[1].Before)
+		assert.Equal(t, []string{"3", "4"}, results[1].After)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		err := parseGrep(strings.NewReader("HEAD:a.txt\n"), nil, 0, 0, func(*GrepResult) error { return nil })
+		assert.Error(t, err)
+	})
+}
+
+func TestRepository_Grep_Options(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	files := map[string]string{
+		"a.txt":   "1\nfoo bar\n3\nfoo\n5\n6\nfoo.*\n",
+		"b.txt":   "foo\nFOO\n",
+		"bin.dat": "\x00foo\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(r.Path(), name), []byte(content), 0600))
+	}
+	require.NoError(t, r.Add(AddOptions{All: true}))
+	require.NoError(t, r.Commit(&Signature{Name: "alice", Email: "alice@example.com"}, "Add files"))
+
+	t.Run("context", func(t *testing.T) {
+		results, err := r.Grep("bar", GrepOptions{BeforeContext: 1, AfterContext: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []*GrepResult{
+			{
+				Tree:   "HEAD",
+				Path:   "a.txt",
+				Line:   2,
+				Column: 5,
+				Text:   "foo bar",
+				Before: []string{"1"},
+				After:  []string{"3", "foo"},
+			},
+		}, results)
+	})
+
+	t.Run("patterns", func(t *testing.T) {
+		results, err := r.Grep("foo", GrepOptions{
+			Pathspec: "a.txt",
+			Patterns: []GrepPattern{{Operator: GrepAnd, Not: true, Pattern: "bar"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, 4, results[0].Line)
+		assert.Equal(t, 7, results[1].Line)
+
+		results, err = r.Grep("bar", GrepOptions{
+			Pathspec: "a.txt",
+			Patterns: []GrepPattern{{Pattern: "^3$"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, 2, results[0].Line)
+		assert.Equal(t, 3, results[1].Line)
+	})
+
+	t.Run("fixed strings", func(t *testing.T) {
+		results, err := r.Grep("foo.*", GrepOptions{FixedStrings: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, 7, results[0].Line)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		results, err := r.Grep("foo", GrepOptions{MaxCountPerFile: 1, SkipBinary: true})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "a.txt", results[0].Path)
+		assert.Equal(t, "b.txt", results[1].Path)
+
+		results, err = r.Grep("foo", GrepOptions{MaxResults: 2})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, 2, results[0].Line)
+		assert.Equal(t, 4, results[1].Line)
+	})
+
+	t.Run("binary", func(t *testing.T) {
+		results, err := r.Grep("foo", GrepOptions{Pathspec: "bin.dat"})
+		require.NoError(t, err)
+		assert.Equal(t, []*GrepResult{{Tree: "HEAD", Path: "bin.dat", Binary: true}}, results)
+
+		results, err = r.Grep("foo", GrepOptions{Pathspec: "bin.dat", BinaryAsText: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.False(t, results[0].Binary)
+		assert.Equal(t, 1, results[0].Line)
+
+		results, err = r.Grep("foo", GrepOptions{Pathspec: "bin.dat", SkipBinary: true})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		results, err := r.Grep("nothing matches")
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		_, err = r.Grep("foo", GrepOptions{Tree: "404"})
+		assert.Error(t, err)
+
+		_, err = r.Grep("foo", GrepOptions{Tree: "--output=/tmp/x"})
+		assert.Error(t, err)
+
+		_, err = r.Grep("(", GrepOptions{ExtendedRegexp: true})
+		assert.Error(t, err)
+	})
+}
+
+func TestRepository_Grep_Trees(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	base, err := r.RevParse("HEAD")
+	require.NoError(t, err)
+
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	for _, branch := range []string{"v1", "v2"} {
+		require.NoError(t, r.Checkout(branch, CheckoutOptions{BaseBranch: base}))
+		err = os.WriteFile(filepath.Join(r.Path(), "needle.txt"), []byte("needle in "+branch+"\n"), 0600)
+		require.NoError(t, err)
+		require.NoError(t, r.Add(AddOptions{All: true}))
+		require.NoError(t, r.Commit(alice, "Add needle to "+branch))
+	}
+
+	results, err := r.Grep("needle", GrepOptions{Trees: []string{"v1", "v2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []*GrepResult{
+		{Tree: "v1", Path: "needle.txt", Line: 1, Column: 1, Text: "needle in v1"},
+		{Tree: "v2", Path: "needle.txt", Line: 1, Column: 1, Text: "needle in v2"},
+	}, results)
+
+	// Trees that are prefixes of others
+	results, err = r.Grep("needle", GrepOptions{Trees: []string{base, "v1", "v1:"}})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "v1", results[0].Tree)
+	assert.Equal(t, "needle.txt", results[0].Path)
+	assert.Equal(t, "v1:", results[1].Tree)
+	assert.Equal(t, "needle.txt", results[1].Path)
+
+	t.Run("stream", func(t *testing.T) {
+		var got []*GrepResult
+		err := r.StreamGrep("needle", func(result *GrepResult) error {
+			got = append(got, result)
+			return nil
+		}, GrepOptions{Trees: []string{"v1", "v2"}})
+		require.NoError(t, err)
+		assert.Equal(t, results[:1], got[:1])
+		assert.Len(t, got, 2)
+
+		errStop := errors.New("stop")
+		calls := 0
+		err = r.StreamGrep("needle", func(*GrepResult) error {
+			calls++
+			return errStop
+		}, GrepOptions{Trees: []string{"v1", "v2"}})
+		assert.Equal(t, errStop, err)
+		assert.Equal(t, 1, calls)
+	})
+}
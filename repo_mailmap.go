@@ -0,0 +1,106 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MailmapOptions contains optional arguments for loading the mailmap.
+//
+// Docs: https://git-scm.com/docs/gitmailmap
+type MailmapOptions struct {
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// configValue returns the value of given config key of the repository, and
+// returns an empty string when the key is not set.
+func (r *Repository) configValue(key string, opt CommandOptions, args ...string) (string, error) {
+	stdout, err := NewCommand("config", "--get").
+		AddOptions(opt).
+		AddArgs(args...).
+		AddArgs("--end-of-options", key).
+		RunInDir(r.path)
+	if err != nil {
+		// Exit status 1 means the key is not set.
+		if msg := err.Error(); msg == "exit status 1" || strings.HasPrefix(msg, "exit status 1 - ") {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(stdout)), nil
+}
+
+// Mailmap loads and returns the mailmap of the repository. The mappings are
+// read in the same order as Git does, with later ones taking precedence:
+//
+//  1. The ".mailmap" in the root of the working tree, for non-bare repositories.
+//  2. The blob specified by "mailmap.blob" config, which defaults to
+//     "HEAD:.mailmap" for bare repositories.
+//  3. The file specified by "mailmap.file" config.
+//
+// Any of them that do not exist are ignored.
+func (r *Repository) Mailmap(opts ...MailmapOptions) (*Mailmap, error) {
+	var opt MailmapOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	stdout, err := NewCommand("rev-parse", "--is-bare-repository").
+		AddOptions(opt.CommandOptions).
+		RunInDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+	bare := strings.TrimSpace(string(stdout)) == "true"
+
+	m := NewMailmap()
+	if !bare {
+		data, err := os.ReadFile(filepath.Join(r.path, ".mailmap"))
+		if err == nil {
+			m.Parse(data)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	blob, err := r.configValue("mailmap.blob", opt.CommandOptions)
+	if err != nil {
+		return nil, err
+	}
+	if blob == "" && bare {
+		blob = "HEAD:.mailmap"
+	}
+	if blob != "" {
+		// The blob may not exist, e.g. an empty repository or no ".mailmap" in HEAD.
+		data, err := NewCommand("cat-file", "blob").
+			AddOptions(opt.CommandOptions).
+			AddArgs("--end-of-options", blob).
+			RunInDir(r.path)
+		if err == nil {
+			m.Parse(data)
+		}
+	}
+
+	file, err := r.configValue("mailmap.file", opt.CommandOptions, "--path")
+	if err != nil {
+		return nil, err
+	}
+	if file != "" {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(r.path, file)
+		}
+		data, err := os.ReadFile(file)
+		if err == nil {
+			m.Parse(data)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return m, nil
+}
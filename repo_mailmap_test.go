@@ -0,0 +1,85 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_Mailmap(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	alice := &Signature{Name: "alice", Email: "alice@old.example.com"}
+
+	t.Run("no mailmap", func(t *testing.T) {
+		m, err := r.Mailmap()
+		require.NoError(t, err)
+		assert.Equal(t, alice, m.Resolve(alice))
+	})
+
+	err = os.WriteFile(filepath.Join(r.Path(), ".mailmap"), []byte("Alice <alice@example.com> <alice@old.example.com>\n"), 0600)
+	require.NoError(t, err)
+
+	t.Run("working tree", func(t *testing.T) {
+		m, err := r.Mailmap()
+		require.NoError(t, err)
+		assert.Equal(t, &Signature{Name: "Alice", Email: "alice@example.com"}, m.Resolve(alice))
+	})
+
+	require.NoError(t, r.Add(AddOptions{All: true}))
+	require.NoError(t, r.Commit(alice, "Add mailmap"))
+
+	t.Run("mailmap.file", func(t *testing.T) {
+		err := os.WriteFile(filepath.Join(r.Path(), "mailmap.txt"), []byte("Alice Cooper <alice@old.example.com>\n"), 0600)
+		require.NoError(t, err)
+		_, err = NewCommand("config", "mailmap.file", "mailmap.txt").RunInDir(r.Path())
+		require.NoError(t, err)
+
+		m, err := r.Mailmap()
+		require.NoError(t, err)
+		assert.Equal(t, &Signature{Name: "Alice Cooper", Email: "alice@example.com"}, m.Resolve(alice))
+	})
+
+	t.Run("bare repository", func(t *testing.T) {
+		path := tempPath()
+		defer func() {
+			_ = os.RemoveAll(path)
+		}()
+		require.NoError(t, Clone(r.Path(), path, CloneOptions{Bare: true}))
+
+		bare, err := Open(path)
+		require.NoError(t, err)
+
+		m, err := bare.Mailmap()
+		require.NoError(t, err)
+		assert.Equal(t, &Signature{Name: "Alice", Email: "alice@example.com"}, m.Resolve(alice))
+
+		t.Run("use mailmap", func(t *testing.T) {
+			commits, err := bare.Log("master", LogOptions{MaxCount: 1, UseMailmap: true})
+			require.NoError(t, err)
+			require.Len(t, commits, 1)
+			assert.Equal(t, "Alice", commits[0].Author.Name)
+			assert.Equal(t, "alice@example.com", commits[0].Author.Email)
+
+			c, err := bare.CatFileCommit("master", CatFileCommitOptions{UseMailmap: true})
+			require.NoError(t, err)
+			assert.Equal(t, "alice@example.com", c.Committer.Email)
+
+			// The cached commit should stay as it is stored
+			c, err = bare.CatFileCommit("master")
+			require.NoError(t, err)
+			assert.Equal(t, "alice@old.example.com", c.Committer.Email)
+		})
+	})
+}
@@ -0,0 +1,288 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CoverFromDescription is the mode to fill the cover letter from the branch
+// description.
+type CoverFromDescription string
+
+// A list of modes to fill the cover letter from the branch description.
+//
+// Docs: https://git-scm.com/docs/git-format-patch#Documentation/git-format-patch.txt---cover-from-descriptionltmodegt
+const (
+	// CoverFromDescriptionMessage puts the whole description in the body and
+	// leaves the placeholder of subject, which is Git's default.
+	CoverFromDescriptionMessage CoverFromDescription = "message"
+	// CoverFromDescriptionSubject uses the first paragraph of the description as
+	// the subject and the rest as the body.
+	CoverFromDescriptionSubject CoverFromDescription = "subject"
+	// CoverFromDescriptionAuto behaves as CoverFromDescriptionSubject unless the
+	// first paragraph is longer than 100 bytes, in which case it behaves as
+	// CoverFromDescriptionMessage.
+	CoverFromDescriptionAuto CoverFromDescription = "auto"
+	// CoverFromDescriptionNone leaves the placeholders of both subject and body.
+	CoverFromDescriptionNone CoverFromDescription = "none"
+)
+
+// FormatPatchOptions contains optional arguments for formatting patches.
+//
+// Docs: https://git-scm.com/docs/git-format-patch
+type FormatPatchOptions struct {
+	// Indicates whether to generate a cover letter before patches.
+	CoverLetter bool
+	// The mode to fill the subject and body of the cover letter from the
+	// description (i.e. "branch.<name>.description") of the branch of head. The
+	// config "format.coverFromDescription" is used when not set. It is only used
+	// when CoverLetter is true.
+	CoverFromDescription CoverFromDescription
+	// The prefix of subjects instead of "PATCH", e.g. "RFC PATCH".
+	SubjectPrefix string
+	// The version of the series, e.g. 2 results in subjects like
+	// "[PATCH v2 1/3]".
+	RerollCount int
+	// Indicates whether to number patches even if there is only one, i.e.
+	// "[PATCH 1/1]".
+	Numbered bool
+	// Indicates whether to make the second and subsequent mails replies to the
+	// first one with the "In-Reply-To" and "References" headers.
+	Thread bool
+	// The Message-ID of the mail that the first mail replies to.
+	InReplyTo string
+	// The recipients of the "To" header.
+	To []string
+	// The recipients of the "Cc" header.
+	Cc []string
+	// Indicates whether to add the "Signed-off-by" trailer of the committer.
+	Signoff bool
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// FormatPatch writes patches of commits in the range (base, head] to given
+// io.Writer as an mbox, one mail per commit in topological order. All commits
+// reachable from head are included when base is empty.
+func (r *Repository) FormatPatch(base, head string, w io.Writer, opts ...FormatPatchOptions) error {
+	var opt FormatPatchOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("format-patch").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--stdout")
+	if opt.CoverLetter {
+		cmd.AddArgs("--cover-letter")
+		if opt.CoverFromDescription != "" {
+			cmd.AddArgs("--cover-from-description=" + string(opt.CoverFromDescription))
+		}
+	}
+	if opt.SubjectPrefix != "" {
+		cmd.AddArgs("--subject-prefix=" + opt.SubjectPrefix)
+	}
+	if opt.RerollCount > 0 {
+		cmd.AddArgs("--reroll-count=" + strconv.Itoa(opt.RerollCount))
+	}
+	if opt.Numbered {
+		cmd.AddArgs("--numbered")
+	}
+	if opt.Thread {
+		cmd.AddArgs("--thread")
+	}
+	if opt.InReplyTo != "" {
+		cmd.AddArgs("--in-reply-to=" + opt.InReplyTo)
+	}
+	for _, to := range opt.To {
+		cmd.AddArgs("--to=" + to)
+	}
+	for _, cc := range opt.Cc {
+		cmd.AddArgs("--cc=" + cc)
+	}
+	if opt.Signoff {
+		cmd.AddArgs("--signoff")
+	}
+	if base == "" {
+		cmd.AddArgs("--root", "--end-of-options", head)
+	} else {
+		cmd.AddArgs("--end-of-options", base+".."+head)
+	}
+
+	stderr := new(bytes.Buffer)
+	if err := cmd.RunInDirPipeline(w, stderr, r.path); err != nil {
+		return concatenateError(err, stderr.String())
+	}
+	return nil
+}
+
+// ApplyMailboxOptions contains optional arguments for applying patches from a
+// mailbox.
+//
+// Docs: https://git-scm.com/docs/git-am
+type ApplyMailboxOptions struct {
+	// The committer of created commits. The identity from the config is used when
+	// not set. The authorship of patches is always preserved. The time of the
+	// committer, when not zero, is used as the commit date of all created
+	// commits.
+	Committer *Signature
+	// Indicates whether to use the author date as the committer date.
+	CommitterDateIsAuthorDate bool
+	// Indicates whether to fall back on three-way merge when a patch does not
+	// apply cleanly. A patch with conflicts is still considered as failed.
+	ThreeWay bool
+	// Indicates whether to add the "Signed-off-by" trailer of the committer.
+	Signoff bool
+	// Indicates whether to skip empty patches, e.g. cover letters, instead of
+	// failing. It requires Git 2.35 or later.
+	SkipEmpty bool
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// ApplyMailboxResult contains the result of applying patches from a mailbox.
+type ApplyMailboxResult struct {
+	// The IDs of commits created from patches in order.
+	Commits []*SHA1
+	// The 1-based index of the mail in the mailbox that fails to apply. It is 0
+	// when all patches are applied.
+	FailedPatch int
+	// The subject of the mail that fails to apply.
+	FailedSubject string
+	// The parts of the failed patch that fail to apply.
+	Rejections []*PatchRejection
+}
+
+// parsePatchFailure returns the subject of the failed patch from the stdout of
+// "git am", e.g. "Patch failed at 0002 Update README".
+func parsePatchFailure(data []byte) string {
+	const prefix = "Patch failed at "
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+
+		fields := strings.SplitN(line[len(prefix):], " ", 2)
+		if len(fields) == 2 {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// ApplyMailbox applies patches from the mailbox to the current branch of the
+// repository, creating one commit per patch. It requires a non-bare repository.
+// It returns ErrPatchRejected along with the failed patch when any patch fails
+// to apply, in which case the repository is restored to the original state.
+func (r *Repository) ApplyMailbox(mailbox io.Reader, opts ...ApplyMailboxOptions) (*ApplyMailboxResult, error) {
+	var opt ApplyMailboxOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// The repository could have no commit yet
+	before, _ := r.RevParse("HEAD")
+
+	cmd := NewCommand("am").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--quiet")
+	if opt.Committer != nil {
+		cmd.AddCommitter(opt.Committer)
+	}
+	if opt.CommitterDateIsAuthorDate {
+		cmd.AddArgs("--committer-date-is-author-date")
+	}
+	if opt.ThreeWay {
+		cmd.AddArgs("--3way")
+	}
+	if opt.Signoff {
+		cmd.AddArgs("--signoff")
+	}
+	if opt.SkipEmpty {
+		cmd.AddArgs("--empty=drop")
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	err := cmd.RunInDirWithOptions(r.path, RunInDirOptions{
+		Stdin:  mailbox,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if err != nil {
+		return r.abortMailbox(err, stdout.Bytes(), stderr.Bytes(), opt)
+	}
+
+	rev := "HEAD"
+	if before != "" {
+		rev = before + "..HEAD"
+	}
+	out, err := NewCommand("rev-list", "--reverse").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--end-of-options", rev).
+		RunInDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := new(ApplyMailboxResult)
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		id, err := NewIDFromString(line)
+		if err != nil {
+			return nil, err
+		}
+		result.Commits = append(result.Commits, id)
+	}
+	return result, nil
+}
+
+// abortMailbox collects the failure of the in-progress "git am" session and
+// aborts it. The given error is returned when there is no such session, i.e.
+// the mailbox failed before any patch.
+func (r *Repository) abortMailbox(amErr error, stdout, stderr []byte, opt ApplyMailboxOptions) (*ApplyMailboxResult, error) {
+	gitPath, err := NewCommand("rev-parse", "--git-path", "rebase-apply").
+		AddOptions(opt.CommandOptions).
+		RunInDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.TrimSpace(string(gitPath))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(r.path, dir)
+	}
+
+	next, err := os.ReadFile(filepath.Join(dir, "next"))
+	if err != nil {
+		return nil, concatenateError(amErr, string(stderr))
+	}
+
+	result := &ApplyMailboxResult{
+		FailedSubject: parsePatchFailure(stdout),
+	}
+	result.FailedPatch, _ = strconv.Atoi(strings.TrimSpace(string(next)))
+	result.Rejections, _ = parseApplyOutput(stderr)
+
+	// Aborting updates the reflog, which needs the committer as well
+	cmd := NewCommand("am", "--abort").AddOptions(opt.CommandOptions)
+	if opt.Committer != nil {
+		cmd.AddCommitter(opt.Committer)
+	}
+	if _, err = cmd.RunInDir(r.path); err != nil {
+		return nil, err
+	}
+	return result, ErrPatchRejected
+}
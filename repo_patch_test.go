@@ -0,0 +1,143 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parsePatchFailure(t *testing.T) {
+	assert.Equal(t, "Update README", parsePatchFailure([]byte("Applying: Add main\nPatch failed at 0002 Update README\nWhen you have resolved this problem, run \"git am --continue\".\n")))
+	assert.Empty(t, parsePatchFailure([]byte("Patch is empty.\n")))
+}
+
+func TestRepository_FormatPatch_ApplyMailbox(t *testing.T) {
+	src, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	dst, cleanup2, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup2()
+
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	bob := &Signature{Name: "bob", Email: "bob@example.com"}
+	for _, name := range []string{"patch1.txt", "patch2.txt"} {
+		err = os.WriteFile(filepath.Join(src.Path(), name), []byte(name+"\n"), 0600)
+		require.NoError(t, err)
+		require.NoError(t, src.Add(AddOptions{All: true}))
+		require.NoError(t, src.Commit(alice, "Add "+name, CommitOptions{Author: bob}))
+	}
+
+	var mbox bytes.Buffer
+	err = src.FormatPatch("HEAD~2", "HEAD", &mbox, FormatPatchOptions{
+		CoverLetter:   true,
+		SubjectPrefix: "RFC PATCH",
+		RerollCount:   2,
+		To:            []string{"list@example.com"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(mbox.String(), "\nSubject: "))
+	assert.Contains(t, mbox.String(), "Subject: [RFC PATCH v2 0/2]")
+	assert.Contains(t, mbox.String(), "Subject: [RFC PATCH v2 1/2] Add patch1.txt\n")
+	assert.Contains(t, mbox.String(), "Subject: [RFC PATCH v2 2/2] Add patch2.txt\n")
+	assert.Contains(t, mbox.String(), "To: list@example.com\n")
+
+	t.Run("single patch", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := src.FormatPatch("HEAD~1", "HEAD", &buf, FormatPatchOptions{Numbered: true})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "Subject: [PATCH 1/1] Add patch2.txt\n")
+	})
+
+	t.Run("cover from description", func(t *testing.T) {
+		branch, err := src.SymbolicRef()
+		require.NoError(t, err)
+		_, err = NewCommand("config", "branch."+strings.TrimPrefix(branch, RefsHeads)+".description", "Support widgets\n\nThis series adds widgets.").RunInDir(src.Path())
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		err = src.FormatPatch("HEAD~2", "HEAD", &buf, FormatPatchOptions{
+			CoverLetter:          true,
+			CoverFromDescription: CoverFromDescriptionSubject,
+			SubjectPrefix:        "PATCH net-next",
+		})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "Subject: [PATCH net-next 0/2] Support widgets\n")
+		assert.Contains(t, buf.String(), "\nThis series adds widgets.\n")
+
+		buf.Reset()
+		err = src.FormatPatch("HEAD~2", "HEAD", &buf, FormatPatchOptions{
+			CoverLetter:          true,
+			CoverFromDescription: CoverFromDescriptionNone,
+		})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "Subject: [PATCH 0/2] *** SUBJECT HERE ***\n")
+		assert.NotContains(t, buf.String(), "This series adds widgets.")
+	})
+
+	t.Run("bad revision", func(t *testing.T) {
+		err := src.FormatPatch("404", "HEAD", new(bytes.Buffer))
+		assert.Error(t, err)
+	})
+
+	head, err := dst.RevParse("HEAD")
+	require.NoError(t, err)
+
+	committer := &Signature{Name: "carol", Email: "carol@example.com"}
+	result, err := dst.ApplyMailbox(bytes.NewReader(mbox.Bytes()), ApplyMailboxOptions{
+		Committer: committer,
+		SkipEmpty: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Commits, 2)
+	assert.Zero(t, result.FailedPatch)
+
+	c, err := dst.CatFileCommit(result.Commits[0].String())
+	require.NoError(t, err)
+	assert.Equal(t, "Add patch1.txt\n", c.Message)
+	assert.Equal(t, "bob", c.Author.Name)
+	original, err := src.CatFileCommit("HEAD~1")
+	require.NoError(t, err)
+	assert.Equal(t, original.Author.When.Unix(), c.Author.When.Unix())
+	assert.Equal(t, "carol", c.Committer.Name)
+	parentID, err := c.ParentID(0)
+	require.NoError(t, err)
+	assert.Equal(t, head, parentID.String())
+
+	t.Run("failed patch", func(t *testing.T) {
+		before, err := dst.RevParse("HEAD")
+		require.NoError(t, err)
+
+		// The files already exist
+		result, err := dst.ApplyMailbox(bytes.NewReader(mbox.Bytes()), ApplyMailboxOptions{
+			Committer: committer,
+			SkipEmpty: true,
+		})
+		assert.Equal(t, ErrPatchRejected, err)
+		assert.Equal(t, 2, result.FailedPatch)
+		assert.Equal(t, "Add patch1.txt", result.FailedSubject)
+		assert.Equal(t, []*PatchRejection{
+			{Path: "patch1.txt", Reason: "already exists in index"},
+		}, result.Rejections)
+
+		after, err := dst.RevParse("HEAD")
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("not a mailbox", func(t *testing.T) {
+		_, err := dst.ApplyMailbox(strings.NewReader("not a mailbox\n"))
+		assert.Error(t, err)
+		assert.NotEqual(t, ErrPatchRejected, err)
+	})
+}
@@ -0,0 +1,233 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// PickaxeOptions contains optional arguments for searching commits that changed
+// given content.
+//
+// Docs: https://git-scm.com/docs/git-log#Documentation/git-log.txt--Sltstringgt
+type PickaxeOptions struct {
+	// The string whose number of occurrences in a file changed in a commit,
+	// i.e. the string was introduced or removed. Moving the string within a file
+	// does not count.
+	Occurrence string
+	// Indicates whether to treat Occurrence as an extended POSIX regular
+	// expression.
+	OccurrenceRegexp bool
+	// The extended POSIX regular expression that matches an added or removed
+	// line in the patch of a commit.
+	PatchRegexp string
+	// Indicates whether to ignore letter case when matching, including Author
+	// and Committer.
+	IgnoreCase bool
+	// The regular expression to filter commits by their authors.
+	Author string
+	// The regular expression to filter commits by their committers.
+	Committer string
+	// To only search commits since the time.
+	Since time.Time
+	// To only search commits until the time.
+	Until time.Time
+	// The pathspecs to limit the search to.
+	Pathspecs []string
+	// The maximum number of commits to output.
+	MaxCount int
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// PickaxeFile is a file in which the searched content changed.
+type PickaxeFile struct {
+	// The path of the file in the state of the commit.
+	Path string
+	// The path of the file before the commit, only set when the file was renamed
+	// or copied in the commit.
+	OldPath string
+	// The type of the change made to the file in the commit.
+	Type DiffFileType
+}
+
+// PickaxeCommit is a commit that changed the searched content.
+type PickaxeCommit struct {
+	*Commit
+	// The files in which the searched content changed.
+	Files []*PickaxeFile
+}
+
+// scanNullTerminated is a bufio.SplitFunc that splits data into fields
+// terminated by NUL bytes.
+func scanNullTerminated(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// parsePickaxe parses the output of "git log --name-status -z
+// --pretty=tformat:%H", and calls fn with each commit ID and its files as soon
+// as the next commit begins, e.g.
+//
+//	<commit>\x00\nM\x00<path>\x00R100\x00<old path>\x00<path>\x00<commit>\x00
+func parsePickaxe(r io.Reader, fn func(id string, files []*PickaxeFile) error) error {
+	var id string
+	var files []*PickaxeFile
+	flush := func() error {
+		if id == "" {
+			return nil
+		}
+		return fn(id, files)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	scanner.Split(scanNullTerminated)
+	next := func() (string, error) {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.ErrUnexpectedEOF
+		}
+		return scanner.Text(), nil
+	}
+
+	for scanner.Scan() {
+		field := string(bytes.TrimLeft(scanner.Bytes(), "\n"))
+		if field == "" {
+			continue
+		}
+
+		// Paths are consumed together with their status, so a field that has the
+		// length of a commit ID starts a new commit.
+		if len(field) == 40 {
+			if err := flush(); err != nil {
+				return err
+			}
+			id = field
+			files = nil
+			continue
+		} else if id == "" {
+			return fmt.Errorf("malformed log: expect commit ID but got %q", field)
+		}
+
+		file := &PickaxeFile{
+			Type: nameStatusType(field[0]),
+		}
+		path, err := next()
+		if err != nil {
+			return fmt.Errorf("malformed log: missing path for %q: %v", field, err)
+		}
+		if field[0] == 'R' || field[0] == 'C' {
+			file.OldPath = path
+			if path, err = next(); err != nil {
+				return fmt.Errorf("malformed log: missing path for %q: %v", field, err)
+			}
+		}
+		file.Path = path
+		files = append(files, file)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// Pickaxe searches commits that changed given content in the state of given
+// revision, and calls fn with each commit as soon as it is found, in reverse
+// chronological order. Either Occurrence or PatchRegexp must be set. It stops
+// and returns the error when fn returns one.
+func (r *Repository) Pickaxe(rev string, fn func(*PickaxeCommit) error, opts ...PickaxeOptions) error {
+	var opt PickaxeOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if opt.Occurrence == "" && opt.PatchRegexp == "" {
+		return errors.New("either occurrence or patch regexp is required")
+	}
+
+	cmd := NewCommand("log").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--name-status", "-z", "--pretty=tformat:%H")
+	if opt.Occurrence != "" {
+		cmd.AddArgs("-S" + opt.Occurrence)
+		if opt.OccurrenceRegexp {
+			cmd.AddArgs("--pickaxe-regex")
+		}
+	}
+	if opt.PatchRegexp != "" {
+		cmd.AddArgs("-G" + opt.PatchRegexp)
+	}
+	if opt.IgnoreCase {
+		cmd.AddArgs("--regexp-ignore-case")
+	}
+	if opt.Author != "" {
+		cmd.AddArgs("--author=" + opt.Author)
+	}
+	if opt.Committer != "" {
+		cmd.AddArgs("--committer=" + opt.Committer)
+	}
+	if !opt.Since.IsZero() {
+		cmd.AddArgs("--since=" + opt.Since.Format(time.RFC3339))
+	}
+	if !opt.Until.IsZero() {
+		cmd.AddArgs("--until=" + opt.Until.Format(time.RFC3339))
+	}
+	if opt.MaxCount > 0 {
+		cmd.AddArgs("--max-count=" + strconv.Itoa(opt.MaxCount))
+	}
+	cmd.AddArgs("--end-of-options", rev, "--")
+	for _, pathspec := range opt.Pathspecs {
+		cmd.AddArgs(escapePath(pathspec))
+	}
+
+	stdout, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := parsePickaxe(stdout, func(id string, files []*PickaxeFile) error {
+			c, err := r.CatFileCommit(id, CatFileCommitOptions{CommandOptions: CommandOptions{Timeout: opt.Timeout}})
+			if err != nil {
+				return err
+			}
+			return fn(&PickaxeCommit{
+				Commit: c,
+				Files:  files,
+			})
+		})
+		// Stop the command when parsing fails, otherwise drain the rest of output
+		// to not block the command.
+		if err != nil {
+			_ = stdout.CloseWithError(err)
+		} else {
+			_, _ = io.Copy(io.Discard, stdout)
+		}
+		done <- err
+	}()
+
+	stderr := new(bytes.Buffer)
+	err := cmd.RunInDirPipeline(w, stderr, r.path)
+	_ = w.Close() // Close writer to exit parsing goroutine
+	parseErr := <-done
+	if parseErr != nil {
+		return parseErr
+	}
+	if err != nil {
+		return concatenateError(err, stderr.String())
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parsePickaxe(t *testing.T) {
+	output := "cfc3b2993f74726356887a5ec093de50486dc617\x00\nM\x00a.txt\x00R090\x00old.txt\x00new.txt\x00" +
+		"755fd577edcfd9209d0ac072eed3b022cbe4d39b\x00\nA\x00b.txt\x00"
+
+	type commit struct {
+		id    string
+		files []*PickaxeFile
+	}
+	var commits []commit
+	err := parsePickaxe(strings.NewReader(output), func(id string, files []*PickaxeFile) error {
+		commits = append(commits, commit{id: id, files: files})
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []commit{
+		{
+			id: "cfc3b2993f74726356887a5ec093de50486dc617",
+			files: []*PickaxeFile{
+				{Path: "a.txt", Type: DiffFileChange},
+				{Path: "new.txt", OldPath: "old.txt", Type: DiffFileRename},
+			},
+		},
+		{
+			id: "755fd577edcfd9209d0ac072eed3b022cbe4d39b",
+			files: []*PickaxeFile{
+				{Path: "b.txt", Type: DiffFileAdd},
+			},
+		},
+	}, commits)
+
+	t.Run("malformed", func(t *testing.T) {
+		noop := func(string, []*PickaxeFile) error { return nil }
+		assert.Error(t, parsePickaxe(strings.NewReader("M\x00a.txt\x00"), noop))
+		assert.Error(t, parsePickaxe(strings.NewReader("cfc3b2993f74726356887a5ec093de50486dc617\x00\nR100\x00a.txt\x00"), noop))
+	})
+}
+
+func TestRepository_Pickaxe(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	bob := &Signature{Name: "bob", Email: "bob@example.com"}
+	commit := func(files map[string]string, message string, author *Signature) string {
+		t.Helper()
+		for name, content := range files {
+			err := os.WriteFile(filepath.Join(r.Path(), name), []byte(content), 0600)
+			require.NoError(t, err)
+		}
+		require.NoError(t, r.Add(AddOptions{All: true}))
+		require.NoError(t, r.Commit(alice, message, CommitOptions{Author: author}))
+		id, err := r.RevParse("HEAD")
+		require.NoError(t, err)
+		return id
+	}
+
+	commit(map[string]string{"a.txt": "1\n2\n3\n"}, "Add a.txt", alice)
+	introduce := commit(map[string]string{"a.txt": "1\n2\n3\nneedle\n"}, "Introduce needle", bob)
+	move := commit(map[string]string{"a.txt": "needle\n1\n2\n3\n"}, "Move needle", alice)
+	relocate := commit(map[string]string{"a.txt": "1\n2\n3\n", "c.txt": "Needle\n"}, "Relocate needle", alice)
+
+	search := func(opt PickaxeOptions) []*PickaxeCommit {
+		t.Helper()
+		var commits []*PickaxeCommit
+		err := r.Pickaxe("HEAD", func(c *PickaxeCommit) error {
+			commits = append(commits, c)
+			return nil
+		}, opt)
+		require.NoError(t, err)
+		return commits
+	}
+	ids := func(commits []*PickaxeCommit) []string {
+		ids := make([]string, 0, len(commits))
+		for _, c := range commits {
+			ids = append(ids, c.ID.String())
+		}
+		return ids
+	}
+
+	t.Run("occurrence", func(t *testing.T) {
+		commits := search(PickaxeOptions{Occurrence: "needle"})
+		assert.Equal(t, []string{relocate, introduce}, ids(commits))
+		assert.Equal(t, []*PickaxeFile{{Path: "a.txt", Type: DiffFileChange}}, commits[0].Files)
+		assert.Equal(t, "Introduce needle", commits[1].Summary())
+
+		commits = search(PickaxeOptions{Occurrence: "needle", IgnoreCase: true})
+		assert.Equal(t, []string{relocate, introduce}, ids(commits))
+		assert.Equal(t, []*PickaxeFile{
+			{Path: "a.txt", Type: DiffFileChange},
+			{Path: "c.txt", Type: DiffFileAdd},
+		}, commits[0].Files)
+
+		commits = search(PickaxeOptions{Occurrence: "ne+dle", OccurrenceRegexp: true})
+		assert.Equal(t, []string{relocate, introduce}, ids(commits))
+	})
+
+	t.Run("patch regexp", func(t *testing.T) {
+		commits := search(PickaxeOptions{PatchRegexp: "ne+dle"})
+		assert.Equal(t, []string{relocate, move, introduce}, ids(commits))
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		commits := search(PickaxeOptions{Occurrence: "needle", Author: "bob"})
+		assert.Equal(t, []string{introduce}, ids(commits))
+
+		commits = search(PickaxeOptions{PatchRegexp: "needle", IgnoreCase: true, Pathspecs: []string{"c.txt"}})
+		assert.Equal(t, []string{relocate}, ids(commits))
+		assert.Equal(t, []*PickaxeFile{{Path: "c.txt", Type: DiffFileAdd}}, commits[0].Files)
+
+		commits = search(PickaxeOptions{PatchRegexp: "needle", MaxCount: 1})
+		assert.Equal(t, []string{relocate}, ids(commits))
+	})
+
+	t.Run("stop by callback", func(t *testing.T) {
+		errStop := errors.New("stop")
+		calls := 0
+		err := r.Pickaxe("HEAD", func(*PickaxeCommit) error {
+			calls++
+			return errStop
+		}, PickaxeOptions{PatchRegexp: "needle"})
+		assert.Equal(t, errStop, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("no pattern", func(t *testing.T) {
+		err := r.Pickaxe("HEAD", func(*PickaxeCommit) error { return nil })
+		assert.Error(t, err)
+	})
+}
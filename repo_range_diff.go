@@ -0,0 +1,236 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// RangeDiffStatus is the status of a pair of commits in a range-diff.
+type RangeDiffStatus uint8
+
+// A list of statuses of pairs of commits in a range-diff.
+const (
+	// RangeDiffUnchanged indicates the commits have the same patch.
+	RangeDiffUnchanged RangeDiffStatus = iota + 1
+	// RangeDiffModified indicates the commits are matched but their patches
+	// differ.
+	RangeDiffModified
+	// RangeDiffRemoved indicates the commit only exists in the old series.
+	RangeDiffRemoved
+	// RangeDiffAdded indicates the commit only exists in the new series.
+	RangeDiffAdded
+)
+
+// RangeDiffPair is a pair of commits of the old and the new series in a
+// range-diff.
+type RangeDiffPair struct {
+	// The status of the pair.
+	Status RangeDiffStatus
+	// The 1-based position of the commit in the old series. It is 0 when the
+	// commit is added.
+	OldIndex int
+	// The ID of the commit in the old series. It is empty when the commit is
+	// added.
+	OldID string
+	// The 1-based position of the commit in the new series. It is 0 when the
+	// commit is removed.
+	NewIndex int
+	// The ID of the commit in the new series. It is empty when the commit is
+	// removed.
+	NewID string
+	// The subject of the commit, which is the one in the new series unless the
+	// commit is removed.
+	Subject string
+	// The sections of the diff between patches of the commits, only set when
+	// modified. Each section is labeled by the part of the patches instead of
+	// line numbers, e.g. "@@ Metadata" or "@@ main.go", and lines are numbered
+	// from the start of the section.
+	Sections []*DiffSection
+}
+
+// parseRangeDiffPosition parses the position and the commit ID of one side of
+// a pair, e.g. "1:" and "4c0af08", or "-:" and "-------".
+func parseRangeDiffPosition(pos, id string) (int, string, error) {
+	pos = strings.TrimSuffix(pos, ":")
+	if pos == "-" {
+		return 0, "", nil
+	}
+
+	index, err := strconv.Atoi(pos)
+	if err != nil {
+		return 0, "", fmt.Errorf("parse position: %v", err)
+	}
+	return index, id, nil
+}
+
+// parseRangeDiffHeader parses the header line of a pair, e.g.
+//
+//	2:  0bc9a29 ! 2:  712e465 Say hello
+func parseRangeDiffHeader(line string) (*RangeDiffPair, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 5 || len(fields[2]) != 1 {
+		return nil, fmt.Errorf("malformed range-diff header: %q", line)
+	}
+
+	pair := new(RangeDiffPair)
+	switch fields[2][0] {
+	case '=':
+		pair.Status = RangeDiffUnchanged
+	case '!':
+		pair.Status = RangeDiffModified
+	case '<':
+		pair.Status = RangeDiffRemoved
+	case '>':
+		pair.Status = RangeDiffAdded
+	default:
+		return nil, fmt.Errorf("malformed range-diff header: unknown status %q", fields[2])
+	}
+
+	var err error
+	pair.OldIndex, pair.OldID, err = parseRangeDiffPosition(fields[0], fields[1])
+	if err != nil {
+		return nil, err
+	}
+	pair.NewIndex, pair.NewID, err = parseRangeDiffPosition(fields[3], fields[4])
+	if err != nil {
+		return nil, err
+	}
+
+	// The subject is everything after the fields, whose spaces are preserved.
+	offset := 0
+	for _, field := range fields[:5] {
+		offset += strings.Index(line[offset:], field) + len(field)
+	}
+	pair.Subject = strings.TrimPrefix(line[offset:], " ")
+	return pair, nil
+}
+
+// parseRangeDiffPatch parses the indented diff between patches of a pair with
+// the diff parser, by making it a diff of a single file.
+func parseRangeDiffPatch(patch []byte) ([]*DiffSection, error) {
+	buf := bytes.NewBuffer(make([]byte, 0, len(patch)+64))
+	buf.WriteString("diff --git a/patch b/patch\nindex 0000000..0000000\n")
+	buf.Write(patch)
+
+	done := make(chan SteamParseDiffResult)
+	go StreamParseDiff(buf, done, 0, 0, 0)
+	result := <-done
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	if len(result.Diff.Files) == 0 {
+		return nil, nil
+	}
+	return result.Diff.Files[0].Sections, nil
+}
+
+// parseRangeDiff parses the output of "git range-diff --no-color", e.g.
+//
+//	1:  4c0af08 = 1:  4c0af08 Add six
+//	2:  0bc9a29 ! 2:  712e465 Say hello
+//	    @@ main.go
+//	      func main() {
+//	     -	println("hi")
+//	    -+	println("hello")
+//	    ++	println("hello, world")
+//	      }
+//	3:  d560fba < -:  ------- Add x
+//	-:  ------- > 3:  8ce7b5c Add y
+func parseRangeDiff(data []byte) ([]*RangeDiffPair, error) {
+	pairs := make([]*RangeDiffPair, 0, 5)
+	patch := new(bytes.Buffer)
+
+	var current *RangeDiffPair
+	flush := func() error {
+		if current == nil || patch.Len() == 0 {
+			return nil
+		}
+
+		var err error
+		current.Sections, err = parseRangeDiffPatch(patch.Bytes())
+		patch.Reset()
+		return err
+	}
+
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		// The diff between patches is indented by four spaces
+		if bytes.HasPrefix(line, []byte("    ")) {
+			if current != nil && current.Status == RangeDiffModified {
+				patch.Write(line[4:])
+				patch.WriteByte('\n')
+			}
+			continue
+		}
+
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		pair, err := parseRangeDiffHeader(string(line))
+		if err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, pair)
+		current = pair
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// RangeDiffOptions contains optional arguments for comparing two versions of a
+// patch series.
+//
+// Docs: https://git-scm.com/docs/git-range-diff
+type RangeDiffOptions struct {
+	// The percentage of the cost of creating or deleting a commit against the
+	// cost of modifying it, higher values match more commits as modified. Git's
+	// default (60) is used when not set.
+	CreationFactor int
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// RangeDiff compares the old series of commits in the range (oldBase, oldHead]
+// with the new series in the range (newBase, newHead], and returns pairs of
+// commits in the order of the new series, with removed commits placed near
+// where they were.
+func (r *Repository) RangeDiff(oldBase, oldHead, newBase, newHead string, opts ...RangeDiffOptions) ([]*RangeDiffPair, error) {
+	var opt RangeDiffOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	// NOTE: "git range-diff" does not support "--end-of-options", thus revisions
+	// must be checked to not be treated as options.
+	for _, rev := range []string{oldBase, oldHead, newBase, newHead} {
+		if strings.HasPrefix(rev, "-") {
+			return nil, fmt.Errorf("invalid revision: %q", rev)
+		}
+	}
+
+	// Use full commit IDs instead of abbreviated ones
+	cmd := NewCommand("-c", "core.abbrev=40", "range-diff").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--no-color")
+	if opt.CreationFactor > 0 {
+		cmd.AddArgs("--creation-factor=" + strconv.Itoa(opt.CreationFactor))
+	}
+	cmd.AddArgs(oldBase+".."+oldHead, newBase+".."+newHead)
+
+	stdout, err := cmd.RunInDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseRangeDiff(stdout)
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseRangeDiff(t *testing.T) {
+	output := `1:  4c0af08 = 1:  4c0af08 Add six
+2:  0bc9a29 ! 2:  712e465 Say  hello
+    @@ main.go
+      
+      func main() {
+     -	println("hi")
+    -+	println("hello")
+    ++	println("hello, world")
+      }
+3:  d560fba < -:  ------- Add x
+-:  ------- > 3:  8ce7b5c Add y
+`
+	pairs, err := parseRangeDiff([]byte(output))
+	require.NoError(t, err)
+	require.Len(t, pairs, 4)
+
+	assert.Equal(t, &RangeDiffPair{
+		Status:   RangeDiffUnchanged,
+		OldIndex: 1,
+		OldID:    "4c0af08",
+		NewIndex: 1,
+		NewID:    "4c0af08",
+		Subject:  "Add six",
+	}, pairs[0])
+
+	modified := pairs[1]
+	assert.Equal(t, RangeDiffModified, modified.Status)
+	assert.Equal(t, "0bc9a29", modified.OldID)
+	assert.Equal(t, "712e465", modified.NewID)
+	assert.Equal(t, "Say  hello", modified.Subject)
+	require.Len(t, modified.Sections, 1)
+	section := modified.Sections[0]
+	require.Len(t, section.Lines, 7)
+	assert.Equal(t, "@@ main.go", section.Lines[0].Content)
+	assert.Equal(t, DiffLineSection, section.Lines[0].Type)
+	assert.Equal(t, DiffLinePlain, section.Lines[3].Type)
+	assert.Equal(t, ` -	println("hi")`, section.Lines[3].Content)
+	assert.Equal(t, DiffLineDelete, section.Lines[4].Type)
+	assert.Equal(t, 4, section.Lines[4].LeftLine)
+	assert.Equal(t, DiffLineAdd, section.Lines[5].Type)
+	assert.Equal(t, 4, section.Lines[5].RightLine)
+
+	assert.Equal(t, &RangeDiffPair{
+		Status:   RangeDiffRemoved,
+		OldIndex: 3,
+		OldID:    "d560fba",
+		Subject:  "Add x",
+	}, pairs[2])
+	assert.Equal(t, &RangeDiffPair{
+		Status:   RangeDiffAdded,
+		NewIndex: 3,
+		NewID:    "8ce7b5c",
+		Subject:  "Add y",
+	}, pairs[3])
+
+	_, err = parseRangeDiff([]byte("1:  4c0af08 ? 1:  4c0af08 Add six\n"))
+	assert.Error(t, err)
+}
+
+func TestRepository_RangeDiff(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	require.NoError(t, err)
+	defer cleanup()
+
+	base, err := r.RevParse("HEAD")
+	require.NoError(t, err)
+
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	commit := func(name, content, message string) {
+		t.Helper()
+		err := os.WriteFile(filepath.Join(r.Path(), name), []byte(content), 0600)
+		require.NoError(t, err)
+		require.NoError(t, r.Add(AddOptions{All: true}))
+		require.NoError(t, r.Commit(alice, message))
+	}
+
+	require.NoError(t, r.Checkout("v1", CheckoutOptions{BaseBranch: base}))
+	commit("six.txt", "6\n", "Add six")
+	commit("hello.txt", "1\n2\nhi\n4\n5\n", "Say hello")
+	commit("x.txt", "x\nxx\nxxx\nxxxx\n", "Add x")
+
+	require.NoError(t, r.Checkout("v2", CheckoutOptions{BaseBranch: base}))
+	commit("six.txt", "6\n", "Add six")
+	commit("hello.txt", "1\n2\nhello\n4\n5\n", "Say hello")
+	commit("y.go", "package y\n\nfunc Y() {}\n", "Add y")
+
+	pairs, err := r.RangeDiff(base, "v1", base, "v2", RangeDiffOptions{CreationFactor: 100})
+	require.NoError(t, err)
+
+	var statuses []RangeDiffStatus
+	var subjects []string
+	for _, pair := range pairs {
+		statuses = append(statuses, pair.Status)
+		subjects = append(subjects, pair.Subject)
+		if pair.OldID != "" {
+			assert.Len(t, pair.OldID, 40)
+		}
+		if pair.NewID != "" {
+			assert.Len(t, pair.NewID, 40)
+		}
+	}
+	assert.Equal(t, []RangeDiffStatus{RangeDiffUnchanged, RangeDiffModified, RangeDiffRemoved, RangeDiffAdded}, statuses)
+	assert.Equal(t, []string{"Add six", "Say hello", "Add x", "Add y"}, subjects)
+	assert.NotEmpty(t, pairs[1].Sections)
+
+	_, err = r.RangeDiff("--output=/tmp/x", "v1", base, "v2")
+	assert.Error(t, err)
+}
@@ -0,0 +1,324 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusEntryType is the type of an entry in the working tree status.
+type StatusEntryType uint8
+
+// A list of different status entry types.
+const (
+	StatusEntryOrdinary StatusEntryType = iota + 1
+	StatusEntryRenamed                  // Renamed or copied
+	StatusEntryUnmerged
+	StatusEntryUntracked
+	StatusEntryIgnored
+)
+
+// StatusCode is the single-letter code of a change in either the index or the
+// working tree.
+type StatusCode byte
+
+// A list of different status codes.
+const (
+	StatusCodeUnmodified  StatusCode = '.'
+	StatusCodeModified    StatusCode = 'M'
+	StatusCodeTypeChanged StatusCode = 'T'
+	StatusCodeAdded       StatusCode = 'A'
+	StatusCodeDeleted     StatusCode = 'D'
+	StatusCodeRenamed     StatusCode = 'R'
+	StatusCodeCopied      StatusCode = 'C'
+	StatusCodeUnmerged    StatusCode = 'U'
+)
+
+// StatusEntry represents a changed, unmerged, untracked or ignored path in the
+// working tree status.
+type StatusEntry struct {
+	// The type of the entry.
+	Type StatusEntryType
+	// The status of the path in the index (staged). It is empty for untracked
+	// and ignored entries.
+	Index StatusCode
+	// The status of the path in the working tree (unstaged). It is empty for
+	// untracked and ignored entries.
+	Worktree StatusCode
+	// The submodule state, "N..." when the path is not a submodule, or
+	// "S<c><m><u>" otherwise.
+	Submodule string
+
+	// The modes of the path in HEAD, the index and the working tree.
+	HeadMode     EntryMode
+	IndexMode    EntryMode
+	WorktreeMode EntryMode
+	// The object IDs of the path in HEAD and the index.
+	HeadID  *SHA1
+	IndexID *SHA1
+
+	// The modes and object IDs of the path in stage 1 (common ancestor), stage 2
+	// (ours) and stage 3 (theirs). They are only set for unmerged entries.
+	StageModes [3]EntryMode
+	StageIDs   [3]*SHA1
+
+	// The similarity score (0-100) of a renamed or copied entry.
+	Score int
+	// The path of the entry.
+	Path string
+	// The original path of a renamed or copied entry.
+	OrigPath string
+}
+
+// IsSubmodule returns true if the entry is a submodule.
+func (e *StatusEntry) IsSubmodule() bool {
+	return strings.HasPrefix(e.Submodule, "S")
+}
+
+// StatusBranch contains the branch header information of the working tree
+// status.
+type StatusBranch struct {
+	// The current commit. It is nil when the branch has no commits yet.
+	Commit *SHA1
+	// The name of the current branch. It is empty when HEAD is detached.
+	Head string
+	// The name of the upstream branch, e.g. "origin/master". It is empty when no
+	// upstream is set.
+	Upstream string
+	// The number of commits ahead of and behind the upstream. They are only
+	// set when the upstream exists.
+	Ahead  int
+	Behind int
+}
+
+// IsDetached returns true if HEAD is detached.
+func (b *StatusBranch) IsDetached() bool {
+	return b.Head == ""
+}
+
+// Status contains the working tree status of a repository.
+type Status struct {
+	// The branch header information.
+	Branch *StatusBranch
+	// The entries in the order produced by Git.
+	Entries []*StatusEntry
+}
+
+// IsClean returns true if there are no changed, unmerged or untracked entries.
+// Ignored entries are not taken into account.
+func (s *Status) IsClean() bool {
+	for _, e := range s.Entries {
+		if e.Type != StatusEntryIgnored {
+			return false
+		}
+	}
+	return true
+}
+
+// parseStatusMode parses an octal mode in status output, e.g. "100644".
+func parseStatusMode(s string) (EntryMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, fmt.Errorf("parse mode %q: %v", s, err)
+	}
+	return EntryMode(mode), nil
+}
+
+// parseStatusBranchHeader parses a "# branch.*" header line into given branch.
+func parseStatusBranchHeader(b *StatusBranch, line string) error {
+	fields := strings.SplitN(line, " ", 3)
+	if len(fields) < 3 {
+		return nil
+	}
+
+	value := fields[2]
+	switch fields[1] {
+	case "branch.oid":
+		if value == "(initial)" {
+			return nil
+		}
+		id, err := NewIDFromString(value)
+		if err != nil {
+			return fmt.Errorf("parse branch.oid: %v", err)
+		}
+		b.Commit = id
+	case "branch.head":
+		if value != "(detached)" {
+			b.Head = value
+		}
+	case "branch.upstream":
+		b.Upstream = value
+	case "branch.ab": // e.g. +1 -2
+		_, err := fmt.Sscanf(value, "+%d -%d", &b.Ahead, &b.Behind)
+		if err != nil {
+			return fmt.Errorf("parse branch.ab: %v", err)
+		}
+	}
+	return nil
+}
+
+// parseStatusChangedEntry parses the fields of an ordinary ("1") or renamed
+// ("2") entry, excluding the leading type, into given entry. The fields are
+// <XY> <sub> <mH> <mI> <mW> <hH> <hI> and followed by <X><score> for renamed
+// entries.
+func parseStatusChangedEntry(e *StatusEntry, fields []string) (err error) {
+	e.Index = StatusCode(fields[0][0])
+	e.Worktree = StatusCode(fields[0][1])
+	e.Submodule = fields[1]
+	if e.HeadMode, err = parseStatusMode(fields[2]); err != nil {
+		return err
+	}
+	if e.IndexMode, err = parseStatusMode(fields[3]); err != nil {
+		return err
+	}
+	if e.WorktreeMode, err = parseStatusMode(fields[4]); err != nil {
+		return err
+	}
+	if e.HeadID, err = NewIDFromString(fields[5]); err != nil {
+		return err
+	}
+	if e.IndexID, err = NewIDFromString(fields[6]); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseStatus parses the output of "git status --porcelain=v2 -z --branch".
+func parseStatus(data []byte) (*Status, error) {
+	status := &Status{
+		Branch: new(StatusBranch),
+	}
+
+	records := bytes.Split(data, []byte{0})
+	for i := 0; i < len(records); i++ {
+		record := string(records[i])
+		if len(record) < 2 {
+			continue
+		}
+
+		if record[0] == '#' {
+			if err := parseStatusBranchHeader(status.Branch, record); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		e := new(StatusEntry)
+		switch record[0] {
+		case '1': // 1 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <path>
+			fields := strings.SplitN(record[2:], " ", 8)
+			if len(fields) != 8 {
+				return nil, fmt.Errorf("malformed ordinary entry: %q", record)
+			}
+			e.Type = StatusEntryOrdinary
+			if err := parseStatusChangedEntry(e, fields); err != nil {
+				return nil, err
+			}
+			e.Path = fields[7]
+
+		case '2': // 2 <XY> <sub> <mH> <mI> <mW> <hH> <hI> <X><score> <path>\0<origPath>
+			fields := strings.SplitN(record[2:], " ", 9)
+			if len(fields) != 9 || i+1 >= len(records) {
+				return nil, fmt.Errorf("malformed renamed entry: %q", record)
+			}
+			e.Type = StatusEntryRenamed
+			if err := parseStatusChangedEntry(e, fields); err != nil {
+				return nil, err
+			}
+			if len(fields[7]) > 1 {
+				e.Score, _ = strconv.Atoi(fields[7][1:])
+			}
+			e.Path = fields[8]
+
+			i++
+			e.OrigPath = string(records[i])
+
+		case 'u': // u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+			fields := strings.SplitN(record[2:], " ", 10)
+			if len(fields) != 10 {
+				return nil, fmt.Errorf("malformed unmerged entry: %q", record)
+			}
+			e.Type = StatusEntryUnmerged
+			e.Index = StatusCode(fields[0][0])
+			e.Worktree = StatusCode(fields[0][1])
+			e.Submodule = fields[1]
+
+			var err error
+			for j := 0; j < 3; j++ {
+				if e.StageModes[j], err = parseStatusMode(fields[2+j]); err != nil {
+					return nil, err
+				}
+				if e.StageIDs[j], err = NewIDFromString(fields[6+j]); err != nil {
+					return nil, err
+				}
+			}
+			if e.WorktreeMode, err = parseStatusMode(fields[5]); err != nil {
+				return nil, err
+			}
+			e.Path = fields[9]
+
+		case '?':
+			e.Type = StatusEntryUntracked
+			e.Path = record[2:]
+
+		case '!':
+			e.Type = StatusEntryIgnored
+			e.Path = record[2:]
+
+		default:
+			return nil, fmt.Errorf("unknown status entry: %q", record)
+		}
+		status.Entries = append(status.Entries, e)
+	}
+	return status, nil
+}
+
+// StatusOptions contains optional arguments for showing the working tree
+// status.
+//
+// Docs: https://git-scm.com/docs/git-status#_porcelain_format_version_2
+type StatusOptions struct {
+	// The mode to show untracked files, i.e. "no", "normal" or "all". Git's
+	// default is used when not set.
+	UntrackedFiles string
+	// Indicates whether to include ignored files.
+	Ignored bool
+	// The specific pathspecs to limit the status to.
+	Pathspecs []string
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// Status returns the working tree status of the repository. It requires a
+// non-bare repository.
+func (r *Repository) Status(opts ...StatusOptions) (*Status, error) {
+	var opt StatusOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd := NewCommand("status").
+		AddOptions(opt.CommandOptions).
+		AddArgs("--porcelain=v2", "-z", "--branch")
+	if opt.UntrackedFiles != "" {
+		cmd.AddArgs("--untracked-files=" + opt.UntrackedFiles)
+	}
+	if opt.Ignored {
+		cmd.AddArgs("--ignored")
+	}
+	if len(opt.Pathspecs) > 0 {
+		cmd.AddArgs("--")
+		cmd.AddArgs(opt.Pathspecs...)
+	}
+
+	stdout, err := cmd.RunInDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseStatus(stdout)
+}
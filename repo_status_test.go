@@ -0,0 +1,166 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseStatus(t *testing.T) {
+	input := strings.Join([]string{
+		"# branch.oid 978fb7f6388b49b532fbef8b856681cfa6fcaa0a",
+		"# branch.head master",
+		"# branch.upstream origin/master",
+		"# branch.ab +2 -1",
+		"1 .M N... 100644 100644 100644 e69de29bb2d1d6434b8b29ae775ad8c2e48c5391 e69de29bb2d1d6434b8b29ae775ad8c2e48c5391 fix me.txt",
+		"2 R. N... 100644 100644 100644 1e24b564bf2298965d8037af42d3ae15ad7d225a 1e24b564bf2298965d8037af42d3ae15ad7d225a R100 docs/README.txt",
+		"README.txt",
+		"u UU N... 100644 100644 100644 100644 e69de29bb2d1d6434b8b29ae775ad8c2e48c5391 1e24b564bf2298965d8037af42d3ae15ad7d225a 6abde17cc19e7a46a6f7c3b7ad4e6b9a3a8e5b0f conflict.txt",
+		"? new.txt",
+		"! build/",
+		"",
+	}, "\x00")
+
+	status, err := parseStatus([]byte(input))
+	require.NoError(t, err)
+
+	want := &Status{
+		Branch: &StatusBranch{
+			Commit:   MustIDFromString("978fb7f6388b49b532fbef8b856681cfa6fcaa0a"),
+			Head:     "master",
+			Upstream: "origin/master",
+			Ahead:    2,
+			Behind:   1,
+		},
+		Entries: []*StatusEntry{
+			{
+				Type:         StatusEntryOrdinary,
+				Index:        StatusCodeUnmodified,
+				Worktree:     StatusCodeModified,
+				Submodule:    "N...",
+				HeadMode:     EntryBlob,
+				IndexMode:    EntryBlob,
+				WorktreeMode: EntryBlob,
+				HeadID:       MustIDFromString("e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"),
+				IndexID:      MustIDFromString("e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"),
+				Path:         "fix me.txt",
+			},
+			{
+				Type:         StatusEntryRenamed,
+				Index:        StatusCodeRenamed,
+				Worktree:     StatusCodeUnmodified,
+				Submodule:    "N...",
+				HeadMode:     EntryBlob,
+				IndexMode:    EntryBlob,
+				WorktreeMode: EntryBlob,
+				HeadID:       MustIDFromString("1e24b564bf2298965d8037af42d3ae15ad7d225a"),
+				IndexID:      MustIDFromString("1e24b564bf2298965d8037af42d3ae15ad7d225a"),
+				Score:        100,
+				Path:         "docs/README.txt",
+				OrigPath:     "README.txt",
+			},
+			{
+				Type:         StatusEntryUnmerged,
+				Index:        StatusCodeUnmerged,
+				Worktree:     StatusCodeUnmerged,
+				Submodule:    "N...",
+				WorktreeMode: EntryBlob,
+				StageModes:   [3]EntryMode{EntryBlob, EntryBlob, EntryBlob},
+				StageIDs: [3]*SHA1{
+					MustIDFromString("e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"),
+					MustIDFromString("1e24b564bf2298965d8037af42d3ae15ad7d225a"),
+					MustIDFromString("6abde17cc19e7a46a6f7c3b7ad4e6b9a3a8e5b0f"),
+				},
+				Path: "conflict.txt",
+			},
+			{
+				Type: StatusEntryUntracked,
+				Path: "new.txt",
+			},
+			{
+				Type: StatusEntryIgnored,
+				Path: "build/",
+			},
+		},
+	}
+	assert.Equal(t, want, status)
+	assert.False(t, status.Branch.IsDetached())
+	assert.False(t, status.IsClean())
+}
+
+func Test_parseStatus_Detached(t *testing.T) {
+	input := "# branch.oid (initial)\x00# branch.head (detached)\x00"
+	status, err := parseStatus([]byte(input))
+	require.NoError(t, err)
+
+	assert.Nil(t, status.Branch.Commit)
+	assert.True(t, status.Branch.IsDetached())
+	assert.Empty(t, status.Branch.Upstream)
+	assert.True(t, status.IsClean())
+}
+
+func TestRepository_Status(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	status, err := r.Status()
+	require.NoError(t, err)
+	assert.True(t, status.IsClean())
+	assert.Equal(t, "master", status.Branch.Head)
+	assert.Equal(t, "origin/master", status.Branch.Upstream)
+	assert.Equal(t, 0, status.Branch.Ahead)
+	assert.Equal(t, 0, status.Branch.Behind)
+
+	head, err := r.RevParse("HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, head, status.Branch.Commit.String())
+
+	// Make some changes to the working tree
+	err = os.WriteFile(filepath.Join(r.Path(), "README.txt"), []byte("status\n"), 0644)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(r.Path(), "untracked.txt"), []byte("untracked\n"), 0644)
+	require.NoError(t, err)
+
+	status, err = r.Status()
+	require.NoError(t, err)
+	assert.False(t, status.IsClean())
+	require.Len(t, status.Entries, 2)
+
+	assert.Equal(t, StatusEntryOrdinary, status.Entries[0].Type)
+	assert.Equal(t, StatusCodeUnmodified, status.Entries[0].Index)
+	assert.Equal(t, StatusCodeModified, status.Entries[0].Worktree)
+	assert.Equal(t, "README.txt", status.Entries[0].Path)
+	assert.False(t, status.Entries[0].IsSubmodule())
+
+	assert.Equal(t, StatusEntryUntracked, status.Entries[1].Type)
+	assert.Equal(t, "untracked.txt", status.Entries[1].Path)
+
+	t.Run("limit to pathspecs", func(t *testing.T) {
+		status, err := r.Status(StatusOptions{
+			Pathspecs: []string{"untracked.txt"},
+		})
+		require.NoError(t, err)
+		require.Len(t, status.Entries, 1)
+		assert.Equal(t, "untracked.txt", status.Entries[0].Path)
+	})
+
+	t.Run("hide untracked files", func(t *testing.T) {
+		status, err := r.Status(StatusOptions{
+			UntrackedFiles: "no",
+		})
+		require.NoError(t, err)
+		require.Len(t, status.Entries, 1)
+		assert.Equal(t, "README.txt", status.Entries[0].Path)
+	})
+}
@@ -45,6 +45,16 @@ l:
 			nextline += eol + 1
 		case eol == 0:
 			tag.message = string(data[nextline+1:])
+
+			// The signature is appended to the message, and the signed payload is
+			// the tag object without the signature.
+			if start := trailingSignatureStart(data[nextline+1:]); start >= 0 {
+				start += nextline + 1
+				tag.signature = &ObjectSignature{
+					Signature: string(data[start:]),
+					Payload:   string(data[:start]),
+				}
+			}
 			break l
 		default:
 			break l
@@ -223,7 +233,13 @@ type CreateTagOptions struct {
 	// Message specifies a tagging message for the annotated tag. It is ignored when tag is not annotated.
 	Message string
 	// Author is the author of the tag. It is ignored when tag is not annotated.
+	// The time of the author, when not zero, is used as the date of the tag.
 	Author *Signature
+	// The signer to sign the tag with, which implies the tag is annotated. The
+	// tag is not signed when not set. Signers that are not supported by Git
+	// natively (i.e. other than GPGSigner and SSHSigner) sign after the tag is
+	// created, and the tag is then updated to the signed one.
+	Signer Signer
 	// The timeout duration before giving up for each shell command execution. The
 	// default timeout duration will be used when not supplied.
 	//
@@ -240,9 +256,14 @@ func (r *Repository) CreateTag(name, rev string, opts ...CreateTagOptions) error
 		opt = opts[0]
 	}
 
-	cmd := NewCommand("tag").AddOptions(opt.CommandOptions)
-	if opt.Annotated {
-		cmd.AddArgs("-a", name)
+	cmd, signing := signingCommand(opt.Signer, "tag")
+	cmd.AddOptions(opt.CommandOptions)
+	if opt.Annotated || opt.Signer != nil {
+		if signing {
+			cmd.AddArgs("-s", name)
+		} else {
+			cmd.AddArgs("-a", name)
+		}
 		cmd.AddArgs("--message", opt.Message)
 		if opt.Author != nil {
 			cmd.AddCommitter(opt.Author)
@@ -255,6 +276,21 @@ func (r *Repository) CreateTag(name, rev string, opts ...CreateTagOptions) error
 	cmd.AddArgs(rev)
 
 	_, err := cmd.RunInDirWithTimeout(opt.Timeout, r.path)
+	if err != nil || opt.Signer == nil || signing {
+		return err
+	}
+
+	// Sign the new tag and update the tag to the signed one.
+	ref := RefsTags + name
+	unsigned, err := r.RevParse(ref, RevParseOptions{Timeout: opt.Timeout}) //nolint
+	if err != nil {
+		return err
+	}
+	signed, err := signObject(r.path, "tag", unsigned, opt.Signer, opt.Timeout)
+	if err != nil {
+		return err
+	}
+	_, err = NewCommand("update-ref", ref, signed.String(), unsigned).RunInDirWithTimeout(opt.Timeout, r.path)
 	return err
 }
 
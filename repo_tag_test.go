@@ -5,11 +5,49 @@
 package git
 
 import (
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func Test_parseTag_Signature(t *testing.T) {
+	payload := `object 0eedd79eba4394bbef888c804e899731644367fe
+type commit
+tag v1.1.0
+tagger Joe Chen <joe@sourcegraph.com> 1581602099 +0800
+
+The version 1.1.0
+`
+	signature := `-----BEGIN PGP SIGNATURE-----
+
+iHUEABYKAB0WIQRDHu/vtApLPSAYNi3uoshV/Tp7ywUCaPMAmwAKCRDuoshV/Tp7
+-----END PGP SIGNATURE-----
+`
+	tag, err := parseTag([]byte(payload + signature))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "Joe Chen", tag.Tagger().Name)
+	if assert.NotNil(t, tag.Signature()) {
+		assert.Equal(t, SignatureFormatOpenPGP, tag.Signature().Format())
+		assert.Equal(t, signature, tag.Signature().Signature)
+		assert.Equal(t, payload, tag.Signature().Payload)
+	}
+
+	t.Run("not signed", func(t *testing.T) {
+		tag, err := parseTag([]byte(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Nil(t, tag.Signature())
+	})
+}
+
 func TestRepository_Tag(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -138,6 +176,86 @@ func TestRepository_CreateAnnotatedTag(t *testing.T) {
 	assert.False(t, tag.tagger.When.IsZero())
 }
 
+func TestRepository_CreateAnnotatedTag_Time(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	author := &Signature{
+		Name:  "alice",
+		Email: "alice@example.com",
+		When:  time.Unix(1581250680, 0).In(time.FixedZone("", 8*3600)),
+	}
+	err = r.CreateTag("v2.0.0", "master", CreateTagOptions{
+		Annotated: true,
+		Message:   "Release v2.0.0",
+		Author:    author,
+	})
+	require.NoError(t, err)
+
+	tag, err := r.Tag("v2.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, author.String(), tag.Tagger().String())
+}
+
+func TestRepository_CreateSignedTag(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	dir := tempPath()
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	key, allowedSigners := setupSSHSigningKey(t, dir, "alice@example.com")
+
+	tests := []struct {
+		name   string
+		tag    string
+		signer Signer
+	}{
+		{
+			name:   "native signer",
+			tag:    "v2.0.0",
+			signer: &SSHSigner{KeyFile: key},
+		},
+		{
+			name:   "external signer",
+			tag:    "v2.0.1",
+			signer: externalSigner{&SSHSigner{KeyFile: key}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := r.CreateTag(test.tag, "master", CreateTagOptions{
+				Message: "Release " + test.tag,
+				Author: &Signature{
+					Name:  "alice",
+					Email: "alice@example.com",
+				},
+				Signer: test.signer,
+			})
+			require.NoError(t, err)
+
+			tag, err := r.Tag(test.tag)
+			require.NoError(t, err)
+			assert.Equal(t, "alice", tag.Tagger().Name)
+			assert.True(t, strings.HasPrefix(tag.Message(), "Release "+test.tag+"\n"))
+			require.NotNil(t, tag.Signature())
+			assert.Equal(t, SignatureFormatSSH, tag.Signature().Format())
+
+			v, err := r.VerifyTag(test.tag, VerifyOptions{AllowedSignersFile: allowedSigners})
+			require.NoError(t, err)
+			assert.True(t, v.Verified)
+		})
+	}
+}
+
 func TestRepository_DeleteTag(t *testing.T) {
 	r, cleanup, err := setupTempRepo()
 	if err != nil {
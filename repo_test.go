@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -455,6 +456,92 @@ func TestRepository_Commit(t *testing.T) {
 		assert.Equal(t, author.Email, c.Author.Email)
 		assert.Equal(t, message+"\n", c.Message)
 	})
+
+	t.Run("with dates", func(t *testing.T) {
+		fpath := filepath.Join(r.Path(), "WITH_DATES")
+		err = ioutil.WriteFile(fpath, []byte("something"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := r.Add(AddOptions{
+			All: true,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		committer := &Signature{
+			Name:  "alice",
+			Email: "alice@example.com",
+			When:  time.Date(2020, 2, 9, 20, 18, 0, 0, time.FixedZone("", 8*3600)),
+		}
+		author := &Signature{
+			Name:  "bob",
+			Email: "bob@example.com",
+			When:  time.Date(2019, 1, 2, 3, 4, 5, 0, time.FixedZone("", -5*3600)),
+		}
+		if err = r.Commit(committer, message, CommitOptions{Author: author}); err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := r.CatFileCommit("master")
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, committer.String(), c.Committer.String())
+		assert.Equal(t, author.String(), c.Author.String())
+	})
+}
+
+func TestRepository_Commit_Signed(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	dir := tempPath()
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	key, allowedSigners := setupSSHSigningKey(t, dir, "alice@example.com")
+
+	committer := &Signature{
+		Name:  "alice",
+		Email: "alice@example.com",
+	}
+	signers := map[string]Signer{
+		"native signer":   &SSHSigner{KeyFile: key},
+		"external signer": externalSigner{&SSHSigner{KeyFile: key}},
+	}
+	for name, signer := range signers {
+		t.Run(name, func(t *testing.T) {
+			head, err := r.RevParse("HEAD")
+			require.NoError(t, err)
+
+			fpath := filepath.Join(r.Path(), "SIGNED")
+			err = os.WriteFile(fpath, []byte(name), 0600)
+			require.NoError(t, err)
+			require.NoError(t, r.Add(AddOptions{All: true}))
+
+			message := "Signed by " + name
+			err = r.Commit(committer, message, CommitOptions{Signer: signer})
+			require.NoError(t, err)
+
+			c, err := r.CatFileCommit("master")
+			require.NoError(t, err)
+			assert.Equal(t, message+"\n", c.Message)
+			parentID, err := c.ParentID(0)
+			require.NoError(t, err)
+			assert.Equal(t, head, parentID.String())
+			require.NotNil(t, c.Signature())
+
+			v, err := r.VerifyCommit("master", VerifyOptions{AllowedSignersFile: allowedSigners})
+			require.NoError(t, err)
+			assert.True(t, v.Verified)
+		})
+	}
 }
 
 func TestRepository_RevParse(t *testing.T) {
@@ -7,6 +7,7 @@ package git
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -160,3 +161,63 @@ func (r *Repository) LsTree(treeID string, opts ...LsTreeOptions) (*Tree, error)
 	r.cachedTrees.Set(treeID, t)
 	return t, nil
 }
+
+// CommitTreeOptions contains optional arguments for creating a commit from a
+// tree.
+//
+// Docs: https://git-scm.com/docs/git-commit-tree
+type CommitTreeOptions struct {
+	// The IDs of parent commits, in order. The commit is a root commit when
+	// empty, and a merge commit when there is more than one parent.
+	Parents []string
+	// The author of the commit. The committer is used when not set.
+	Author *Signature
+	// The committer of the commit. The identity from the config is used when not
+	// set.
+	Committer *Signature
+	// The signer to sign the commit with. The commit is not signed when not set.
+	Signer Signer
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// CommitTree creates a new commit object of given tree with the message,
+// and returns the ID of the new commit. It does not need a working tree and
+// does not update any reference, thus it is suitable for bare repositories.
+func (r *Repository) CommitTree(treeID, message string, opts ...CommitTreeOptions) (*SHA1, error) {
+	var opt CommitTreeOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	cmd, signing := signingCommand(opt.Signer, "commit-tree")
+	cmd.AddOptions(opt.CommandOptions).
+		AddArgs("-m", message)
+	if signing {
+		cmd.AddArgs("-S")
+	}
+	for _, parent := range opt.Parents {
+		cmd.AddArgs("-p", parent)
+	}
+	cmd.AddArgs("--end-of-options", treeID)
+
+	if opt.Committer != nil {
+		cmd.AddEnvs(signatureEnvs("COMMITTER", opt.Committer)...)
+		if opt.Author == nil {
+			opt.Author = opt.Committer
+		}
+	}
+	if opt.Author != nil {
+		cmd.AddEnvs(signatureEnvs("AUTHOR", opt.Author)...)
+	}
+
+	stdout, err := cmd.RunInDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+	id, err := NewIDFromString(strings.TrimSpace(string(stdout)))
+	if err != nil || opt.Signer == nil || signing {
+		return id, err
+	}
+	return signObject(r.path, "commit", id.String(), opt.Signer, opt.Timeout)
+}
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -93,3 +94,92 @@ func TestRepository_LsTree(t *testing.T) {
 	require.Len(t, entries, 1)
 	assert.Equal(t, specialName, entries[0].Name())
 }
+
+func TestRepository_CommitTree(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	head, err := r.CatFileCommit("master")
+	require.NoError(t, err)
+	treeID := head.Tree.id.String()
+	parentID, err := head.ParentID(0)
+	require.NoError(t, err)
+
+	committer := &Signature{
+		Name:  "alice",
+		Email: "alice@example.com",
+		When:  time.Unix(1581250680, 0).In(time.FixedZone("", 8*3600)),
+	}
+	author := &Signature{
+		Name:  "bob",
+		Email: "bob@example.com",
+		When:  time.Unix(1581250000, 0).In(time.FixedZone("", -5*3600)),
+	}
+
+	t.Run("root commit", func(t *testing.T) {
+		id, err := r.CommitTree(treeID, "Root commit", CommitTreeOptions{
+			Committer: committer,
+		})
+		require.NoError(t, err)
+
+		c, err := r.CatFileCommit(id.String())
+		require.NoError(t, err)
+		assert.Equal(t, "Root commit\n", c.Message)
+		assert.Equal(t, 0, c.ParentsCount())
+		assert.Equal(t, treeID, c.Tree.id.String())
+		assert.Equal(t, "alice", c.Author.Name)
+		assert.Equal(t, committer.When.Unix(), c.Committer.When.Unix())
+		assert.Nil(t, c.Signature())
+	})
+
+	t.Run("merge commit", func(t *testing.T) {
+		id, err := r.CommitTree(treeID, "Merge commit", CommitTreeOptions{
+			Parents:   []string{head.ID.String(), parentID.String()},
+			Author:    author,
+			Committer: committer,
+		})
+		require.NoError(t, err)
+
+		c, err := r.CatFileCommit(id.String())
+		require.NoError(t, err)
+		assert.Equal(t, 2, c.ParentsCount())
+		assert.Equal(t, author.String(), c.Author.String())
+		assert.Equal(t, committer.String(), c.Committer.String())
+	})
+
+	dir := tempPath()
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	key, allowedSigners := setupSSHSigningKey(t, dir, "alice@example.com")
+
+	signers := map[string]Signer{
+		"native signer":   &SSHSigner{KeyFile: key},
+		"external signer": externalSigner{&SSHSigner{KeyFile: key}},
+	}
+	for name, signer := range signers {
+		t.Run(name, func(t *testing.T) {
+			id, err := r.CommitTree(treeID, "Signed commit", CommitTreeOptions{
+				Parents:   []string{head.ID.String()},
+				Committer: committer,
+				Signer:    signer,
+			})
+			require.NoError(t, err)
+
+			c, err := r.CatFileCommit(id.String())
+			require.NoError(t, err)
+			assert.Equal(t, "Signed commit\n", c.Message)
+			require.NotNil(t, c.Signature())
+			assert.Equal(t, SignatureFormatSSH, c.Signature().Format())
+
+			v, err := r.VerifyCommit(id.String(), VerifyOptions{AllowedSignersFile: allowedSigners})
+			require.NoError(t, err)
+			assert.True(t, v.Verified)
+			assert.Equal(t, "alice@example.com", v.Signer)
+		})
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// TrustLevel is the trust level of the key that made a signature.
+type TrustLevel string
+
+// A list of trust levels, which have the same values as the
+// "gpg.minTrustLevel" config.
+const (
+	TrustUndefined TrustLevel = "undefined"
+	TrustNever     TrustLevel = "never"
+	TrustMarginal  TrustLevel = "marginal"
+	TrustFully     TrustLevel = "fully"
+	TrustUltimate  TrustLevel = "ultimate"
+)
+
+// SignatureVerification contains the result of verifying the signature of a
+// commit or tag.
+type SignatureVerification struct {
+	// Indicates whether the signature is good and made by a known key.
+	Verified bool
+	// The format of the signature.
+	Format SignatureFormat
+	// The identity of the signer, i.e. the user ID of the OpenPGP key (e.g.
+	// "Alice <alice@example.com>"), or the principal in the SSH allowed signers
+	// file.
+	Signer string
+	// The ID of the key that made the signature, i.e. the long key ID of the
+	// OpenPGP key, or the fingerprint of the SSH key.
+	KeyID string
+	// The fingerprint of the primary key, only set for OpenPGP and X.509 keys.
+	Fingerprint string
+	// The trust level of the key.
+	TrustLevel TrustLevel
+	// The reason of the failure when the signature is not verified.
+	Reason string
+}
+
+// parseGPGVerification parses the GnuPG status lines (i.e. "--status-fd") of
+// verifying a signature.
+//
+// Docs: https://github.com/gpg/gnupg/blob/master/doc/DETAILS
+func parseGPGVerification(v *SignatureVerification, output []byte) {
+	reasons := map[string]string{
+		"BADSIG":     "bad signature",
+		"EXPSIG":     "signature has expired",
+		"EXPKEYSIG":  "key has expired",
+		"REVKEYSIG":  "key has been revoked",
+		"ERRSIG":     "signature could not be checked",
+		"NO_PUBKEY":  "public key not found",
+		"FAILURE":    "verification failed",
+		"NODATA":     "no signature data found",
+		"BADARMOR":   "signature is malformed",
+		"UNEXPECTED": "signature is malformed",
+	}
+
+	var goodSig bool
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "[GNUPG:] ") {
+			continue
+		}
+
+		fields := strings.SplitN(line[len("[GNUPG:] "):], " ", 3)
+		switch keyword := fields[0]; keyword {
+		case "GOODSIG", "BADSIG", "EXPSIG", "EXPKEYSIG", "REVKEYSIG":
+			// e.g. GOODSIG <long key ID> <user ID>
+			goodSig = keyword == "GOODSIG"
+			if len(fields) > 1 {
+				v.KeyID = fields[1]
+			}
+			if len(fields) > 2 {
+				v.Signer = fields[2]
+			}
+		case "ERRSIG", "NO_PUBKEY":
+			// e.g. ERRSIG <long key ID> <pkalgo> ...
+			if len(fields) > 1 && v.KeyID == "" {
+				v.KeyID = fields[1]
+			}
+		case "VALIDSIG":
+			// The last field is the fingerprint of the primary key.
+			fields := strings.Fields(line)
+			v.Fingerprint = fields[len(fields)-1]
+		default:
+			if strings.HasPrefix(keyword, "TRUST_") {
+				v.TrustLevel = TrustLevel(strings.ToLower(keyword[len("TRUST_"):]))
+			}
+		}
+
+		if reason, ok := reasons[fields[0]]; ok && v.Reason == "" {
+			v.Reason = reason
+		}
+	}
+
+	v.Verified = goodSig && v.Reason == ""
+	if !v.Verified && v.Reason == "" {
+		v.Reason = "no valid signature found"
+	}
+}
+
+// parseSSHVerification parses the output of "ssh-keygen -Y verify" relayed by
+// Git, e.g.
+//
+//	Good "git" signature for alice@example.com with ED25519 key SHA256:LHJ1fj3...
+//	Good "git" signature with ED25519 key SHA256:LHJ1fj3...
+//	No principal matched.
+func parseSSHVerification(v *SignatureVerification, output []byte) {
+	const goodPrefix = `Good "git" signature `
+
+	var lastLine string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		lastLine = line
+
+		if !strings.HasPrefix(line, goodPrefix) {
+			continue
+		}
+
+		rest := line[len(goodPrefix):]
+		if strings.HasPrefix(rest, "for ") {
+			i := strings.LastIndex(rest, " with ")
+			if i > len("for ") {
+				v.Signer = rest[len("for "):i]
+				v.Verified = true
+				v.TrustLevel = TrustFully
+			}
+		}
+		if i := strings.LastIndex(rest, " key "); i >= 0 {
+			v.KeyID = rest[i+len(" key "):]
+		}
+	}
+
+	if !v.Verified {
+		v.TrustLevel = TrustUndefined
+		v.Reason = lastLine
+		if v.Reason == "" {
+			v.Reason = "no valid signature found"
+		}
+	}
+}
+
+// VerifyOptions contains optional arguments for verifying the signature of a
+// commit or tag.
+//
+// Docs: https://git-scm.com/docs/git-verify-commit
+type VerifyOptions struct {
+	// The GnuPG home directory that contains the keyring to verify OpenPGP and
+	// X.509 signatures. The default of GnuPG is used when not set.
+	GPGHome string
+	// The path of the allowed signers file to verify SSH signatures. The
+	// "gpg.ssh.allowedSignersFile" config is used when not set.
+	AllowedSignersFile string
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// verify runs given verify subcommand on the object with given signature.
+func (r *Repository) verify(subcommand, id string, sig *ObjectSignature, opt VerifyOptions) (*SignatureVerification, error) {
+	var cmd *Command
+	if opt.AllowedSignersFile != "" {
+		cmd = NewCommand("-c", "gpg.ssh.allowedSignersFile="+opt.AllowedSignersFile, subcommand)
+	} else {
+		cmd = NewCommand(subcommand)
+	}
+	cmd.AddOptions(opt.CommandOptions).
+		AddArgs("--raw", "--end-of-options", id)
+	if opt.GPGHome != "" {
+		cmd.AddEnvs("GNUPGHOME=" + opt.GPGHome)
+	}
+
+	// The verification outputs go to stderr regardless of the result.
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	err := cmd.RunInDirPipeline(stdout, stderr, r.path)
+
+	v := &SignatureVerification{
+		Format: sig.Format(),
+	}
+	output := stderr.Bytes()
+	switch v.Format {
+	case SignatureFormatSSH:
+		// Errors from Git itself indicate the verification could not be done, e.g.
+		// the allowed signers file is not configured.
+		if err != nil && (bytes.HasPrefix(output, []byte("error: ")) || bytes.HasPrefix(output, []byte("fatal: "))) {
+			return nil, concatenateError(err, stderr.String())
+		}
+		parseSSHVerification(v, output)
+	case SignatureFormatOpenPGP, SignatureFormatX509:
+		if err != nil && !bytes.Contains(output, []byte("[GNUPG:] ")) {
+			return nil, concatenateError(err, stderr.String())
+		}
+		parseGPGVerification(v, output)
+	default:
+		return nil, errors.New("unknown signature format")
+	}
+	return v, nil
+}
+
+// VerifyCommit verifies the signature of the commit by given revision. It
+// returns ErrNotSigned if the commit is not signed. A bad signature is not an
+// error but reported by the returned verification. Like Git in a SHA-1
+// repository, only the "gpgsig" header is verified but not "gpgsig-sha256".
+func (r *Repository) VerifyCommit(rev string, opts ...VerifyOptions) (*SignatureVerification, error) {
+	var opt VerifyOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	commit, err := r.CatFileCommit(rev, CatFileCommitOptions{Timeout: opt.Timeout}) //nolint
+	if err != nil {
+		return nil, err
+	} else if commit.Signature() == nil {
+		return nil, ErrNotSigned
+	}
+	return r.verify("verify-commit", commit.ID.String(), commit.Signature(), opt)
+}
+
+// VerifyTag verifies the signature of the tag by given name, e.g. "v1.0.0". It
+// returns ErrNotSigned if the tag is not signed. A bad signature is not an
+// error but reported by the returned verification.
+func (r *Repository) VerifyTag(name string, opts ...VerifyOptions) (*SignatureVerification, error) {
+	var opt VerifyOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	tag, err := r.Tag(name, TagOptions{Timeout: opt.Timeout}) //nolint
+	if err != nil {
+		return nil, err
+	} else if tag.Signature() == nil {
+		return nil, ErrNotSigned
+	}
+	return r.verify("verify-tag", tag.ID().String(), tag.Signature(), opt)
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseGPGVerification(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *SignatureVerification
+	}{
+		{
+			name: "good signature",
+			output: `[GNUPG:] NEWSIG bob@example.com
+[GNUPG:] KEY_CONSIDERED 431EF1EFB40A4B3D2018362DEEA2C855FD3A7BCB 0
+[GNUPG:] GOODSIG EEA2C855FD3A7BCB Bob <bob@example.com>
+[GNUPG:] VALIDSIG 431EF1EFB40A4B3D2018362DEEA2C855FD3A7BCB 2026-10-18 1792327707 0 4 0 22 8 00 431EF1EFB40A4B3D2018362DEEA2C855FD3A7BCB
+[GNUPG:] TRUST_ULTIMATE 0 pgp
+`,
+			want: &SignatureVerification{
+				Verified:    true,
+				Signer:      "Bob <bob@example.com>",
+				KeyID:       "EEA2C855FD3A7BCB",
+				Fingerprint: "431EF1EFB40A4B3D2018362DEEA2C855FD3A7BCB",
+				TrustLevel:  TrustUltimate,
+			},
+		},
+		{
+			name: "bad signature",
+			output: `[GNUPG:] NEWSIG
+[GNUPG:] BADSIG EEA2C855FD3A7BCB Bob <bob@example.com>
+`,
+			want: &SignatureVerification{
+				Signer: "Bob <bob@example.com>",
+				KeyID:  "EEA2C855FD3A7BCB",
+				Reason: "bad signature",
+			},
+		},
+		{
+			name: "no public key",
+			output: `[GNUPG:] NEWSIG
+[GNUPG:] ERRSIG EEA2C855FD3A7BCB 22 10 00 1792327707 9 -
+[GNUPG:] NO_PUBKEY EEA2C855FD3A7BCB
+`,
+			want: &SignatureVerification{
+				KeyID:  "EEA2C855FD3A7BCB",
+				Reason: "signature could not be checked",
+			},
+		},
+		{
+			name:   "nothing",
+			output: "",
+			want: &SignatureVerification{
+				Reason: "no valid signature found",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := new(SignatureVerification)
+			parseGPGVerification(v, []byte(test.output))
+			assert.Equal(t, test.want, v)
+		})
+	}
+}
+
+func Test_parseSSHVerification(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *SignatureVerification
+	}{
+		{
+			name:   "good signature",
+			output: `Good "git" signature for alice@example.com with ED25519 key SHA256:LHJ1fj3/aQvznWOL0CtVXe5VUB30Wl5b2DgvILdRy+Y`,
+			want: &SignatureVerification{
+				Verified:   true,
+				Signer:     "alice@example.com",
+				KeyID:      "SHA256:LHJ1fj3/aQvznWOL0CtVXe5VUB30Wl5b2DgvILdRy+Y",
+				TrustLevel: TrustFully,
+			},
+		},
+		{
+			name: "no principal matched",
+			output: `Good "git" signature with ED25519 key SHA256:LHJ1fj3/aQvznWOL0CtVXe5VUB30Wl5b2DgvILdRy+Y
+No principal matched.
+`,
+			want: &SignatureVerification{
+				KeyID:      "SHA256:LHJ1fj3/aQvznWOL0CtVXe5VUB30Wl5b2DgvILdRy+Y",
+				TrustLevel: TrustUndefined,
+				Reason:     "No principal matched.",
+			},
+		},
+		{
+			name:   "bad signature",
+			output: "Signature verification failed: incorrect signature\n",
+			want: &SignatureVerification{
+				TrustLevel: TrustUndefined,
+				Reason:     "Signature verification failed: incorrect signature",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := new(SignatureVerification)
+			parseSSHVerification(v, []byte(test.output))
+			assert.Equal(t, test.want, v)
+		})
+	}
+}
+
+// setupSSHSigningKey generates a new SSH key pair in given directory, and
+// returns the path of the private key and the allowed signers file that allows
+// the key for given principal.
+func setupSSHSigningKey(t *testing.T, dir, principal string) (key, allowedSigners string) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("Skipping testing without ssh-keygen")
+	}
+
+	key = filepath.Join(dir, "id_ed25519")
+	err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", principal, "-f", key).Run()
+	require.NoError(t, err)
+
+	pub, err := os.ReadFile(key + ".pub")
+	require.NoError(t, err)
+
+	allowedSigners = filepath.Join(dir, "allowed_signers")
+	err = os.WriteFile(allowedSigners, append([]byte(principal+" "), pub...), 0600)
+	require.NoError(t, err)
+	return key, allowedSigners
+}
+
+func TestRepository_Verify_SSH(t *testing.T) {
+	r, cleanup, err := setupTempRepo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	dir := tempPath()
+	require.NoError(t, os.MkdirAll(dir, os.ModePerm))
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	key, allowedSigners := setupSSHSigningKey(t, dir, "alice@example.com")
+
+	committer := &Signature{Name: "alice", Email: "alice@example.com"}
+	_, err = NewCommand("-c", "gpg.format=ssh", "-c", "user.signingKey="+key, "commit").
+		AddCommitter(committer).
+		AddArgs("--author=alice <alice@example.com>", "--allow-empty", "-S", "-m", "Signed commit").
+		RunInDir(r.Path())
+	require.NoError(t, err)
+	_, err = NewCommand("-c", "gpg.format=ssh", "-c", "user.signingKey="+key, "tag").
+		AddCommitter(committer).
+		AddArgs("-s", "-m", "Signed tag", "v-signed").
+		RunInDir(r.Path())
+	require.NoError(t, err)
+
+	t.Run("commit", func(t *testing.T) {
+		c, err := r.CatFileCommit("HEAD")
+		require.NoError(t, err)
+		require.NotNil(t, c.Signature())
+		assert.Equal(t, SignatureFormatSSH, c.Signature().Format())
+
+		v, err := r.VerifyCommit("HEAD", VerifyOptions{AllowedSignersFile: allowedSigners})
+		require.NoError(t, err)
+		assert.True(t, v.Verified)
+		assert.Equal(t, SignatureFormatSSH, v.Format)
+		assert.Equal(t, "alice@example.com", v.Signer)
+		assert.Contains(t, v.KeyID, "SHA256:")
+		assert.Equal(t, TrustFully, v.TrustLevel)
+		assert.Empty(t, v.Reason)
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		tag, err := r.Tag("v-signed")
+		require.NoError(t, err)
+		require.NotNil(t, tag.Signature())
+		assert.Equal(t, "Signed tag\n", tag.Signature().Payload[len(tag.Signature().Payload)-len("Signed tag\n"):])
+
+		v, err := r.VerifyTag("v-signed", VerifyOptions{AllowedSignersFile: allowedSigners})
+		require.NoError(t, err)
+		assert.True(t, v.Verified)
+		assert.Equal(t, "alice@example.com", v.Signer)
+	})
+
+	t.Run("unknown signer", func(t *testing.T) {
+		otherDir := filepath.Join(dir, "other")
+		require.NoError(t, os.MkdirAll(otherDir, os.ModePerm))
+		_, otherSigners := setupSSHSigningKey(t, otherDir, "bob@example.com")
+		v, err := r.VerifyCommit("HEAD", VerifyOptions{AllowedSignersFile: otherSigners})
+		require.NoError(t, err)
+		assert.False(t, v.Verified)
+		assert.NotEmpty(t, v.Reason)
+	})
+
+	t.Run("not signed", func(t *testing.T) {
+		_, err := r.VerifyCommit("HEAD~1", VerifyOptions{AllowedSignersFile: allowedSigners})
+		assert.Equal(t, ErrNotSigned, err)
+	})
+}
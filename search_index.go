@@ -0,0 +1,719 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// trigram is three consecutive bytes packed into an integer.
+type trigram uint32
+
+// trigramsOf returns the distinct trigrams of given content.
+func trigramsOf(content []byte) []trigram {
+	if len(content) < 3 {
+		return nil
+	}
+
+	seen := make(map[trigram]struct{}, len(content)/2)
+	trigrams := make([]trigram, 0, len(content)/2)
+	for i := 0; i+3 <= len(content); i++ {
+		t := trigram(content[i])<<16 | trigram(content[i+1])<<8 | trigram(content[i+2])
+		if _, ok := seen[t]; ok {
+			continue
+		}
+		seen[t] = struct{}{}
+		trigrams = append(trigrams, t)
+	}
+	return trigrams
+}
+
+// trigramQueryOp is the operator of a trigram query.
+type trigramQueryOp uint8
+
+const (
+	// trigramAll matches all files.
+	trigramAll trigramQueryOp = iota
+	// trigramAnd matches files that have all the trigrams and match all the
+	// subqueries.
+	trigramAnd
+	// trigramOr matches files that have any of the trigrams or match any of the
+	// subqueries.
+	trigramOr
+)
+
+// trigramQuery is a query over trigrams of files, which matches a superset of
+// files that match a regular expression.
+type trigramQuery struct {
+	op       trigramQueryOp
+	trigrams []trigram
+	subs     []*trigramQuery
+}
+
+var matchAllQuery = &trigramQuery{op: trigramAll}
+
+// andQuery returns the query that matches files matching both queries.
+func andQuery(q1, q2 *trigramQuery) *trigramQuery {
+	if q1.op == trigramAll {
+		return q2
+	} else if q2.op == trigramAll {
+		return q1
+	}
+	return &trigramQuery{op: trigramAnd, subs: []*trigramQuery{q1, q2}}
+}
+
+// orQuery returns the query that matches files matching either query.
+func orQuery(q1, q2 *trigramQuery) *trigramQuery {
+	if q1.op == trigramAll || q2.op == trigramAll {
+		return matchAllQuery
+	}
+	return &trigramQuery{op: trigramOr, subs: []*trigramQuery{q1, q2}}
+}
+
+// stringsQuery returns the query that matches files containing any of given
+// strings. It matches all files when any string is shorter than a trigram.
+func stringsQuery(ss []string) *trigramQuery {
+	if len(ss) == 0 {
+		return matchAllQuery
+	}
+
+	q := &trigramQuery{op: trigramOr}
+	for _, s := range ss {
+		if len(s) < 3 {
+			return matchAllQuery
+		}
+		q.subs = append(q.subs, &trigramQuery{op: trigramAnd, trigrams: trigramsOf([]byte(s))})
+	}
+	if len(q.subs) == 1 {
+		return q.subs[0]
+	}
+	return q
+}
+
+// maxExactStrings is the maximum number of exact strings to track while
+// analyzing a regular expression, beyond which they are turned into a query.
+const maxExactStrings = 16
+
+// regexpInfo is the result of analyzing a regular expression.
+type regexpInfo struct {
+	// The exact set of strings the expression matches, nil when unknown.
+	exact []string
+	// The query that is necessary for files to match the expression, in
+	// addition to containing any of the exact strings.
+	query *trigramQuery
+}
+
+// toQuery returns the query that is necessary for files to match.
+func (info regexpInfo) toQuery() *trigramQuery {
+	if info.exact == nil {
+		return info.query
+	}
+	return andQuery(info.query, stringsQuery(info.exact))
+}
+
+// analyzeRegexp analyzes the simplified regular expression for the strings it
+// matches. All strings are lowered as the index is case-insensitive.
+func analyzeRegexp(re *syntax.Regexp) regexpInfo {
+	unknown := regexpInfo{query: matchAllQuery}
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return regexpInfo{exact: []string{""}, query: matchAllQuery}
+
+	case syntax.OpLiteral:
+		return regexpInfo{exact: []string{strings.ToLower(string(re.Rune))}, query: matchAllQuery}
+
+	case syntax.OpCharClass:
+		var exact []string
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				if len(exact) >= maxExactStrings {
+					return unknown
+				}
+				exact = append(exact, strings.ToLower(string(r)))
+			}
+		}
+		return regexpInfo{exact: dedupStrings(exact), query: matchAllQuery}
+
+	case syntax.OpCapture:
+		return analyzeRegexp(re.Sub[0])
+
+	case syntax.OpPlus:
+		// At least one occurrence is required
+		return regexpInfo{query: analyzeRegexp(re.Sub[0]).toQuery()}
+
+	case syntax.OpRepeat:
+		if re.Min == 0 {
+			return unknown
+		}
+		return regexpInfo{query: analyzeRegexp(re.Sub[0]).toQuery()}
+
+	case syntax.OpConcat:
+		info := regexpInfo{exact: []string{""}, query: matchAllQuery}
+		for _, sub := range re.Sub {
+			next := analyzeRegexp(sub)
+			if info.exact != nil && next.exact != nil && len(info.exact)*len(next.exact) <= maxExactStrings {
+				exact := make([]string, 0, len(info.exact)*len(next.exact))
+				for _, s1 := range info.exact {
+					for _, s2 := range next.exact {
+						exact = append(exact, s1+s2)
+					}
+				}
+				info = regexpInfo{
+					exact: dedupStrings(exact),
+					query: andQuery(info.query, next.query),
+				}
+				continue
+			}
+
+			info = regexpInfo{query: andQuery(info.toQuery(), next.toQuery())}
+		}
+		return info
+
+	case syntax.OpAlternate:
+		info := analyzeRegexp(re.Sub[0])
+		for _, sub := range re.Sub[1:] {
+			next := analyzeRegexp(sub)
+			if info.exact != nil && next.exact != nil && len(info.exact)+len(next.exact) <= maxExactStrings &&
+				info.query.op == trigramAll && next.query.op == trigramAll {
+				info = regexpInfo{
+					exact: dedupStrings(append(info.exact, next.exact...)),
+					query: matchAllQuery,
+				}
+				continue
+			}
+
+			info = regexpInfo{query: orQuery(info.toQuery(), next.toQuery())}
+		}
+		return info
+	}
+
+	// Including OpAnyChar, OpStar, OpQuest and so on
+	return unknown
+}
+
+// dedupStrings returns the sorted distinct strings.
+func dedupStrings(ss []string) []string {
+	sort.Strings(ss)
+	out := ss[:0]
+	for i, s := range ss {
+		if i == 0 || s != ss[i-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// SearchIndexOptions contains optional arguments for building a search index.
+type SearchIndexOptions struct {
+	// The maximum size in bytes of files to be indexed, larger files are skipped.
+	// Defaults to 1 MiB.
+	MaxFileSize int64
+	// The additional options to be passed to the underlying git.
+	CommandOptions
+}
+
+// indexedFile is a file in the search index.
+type indexedFile struct {
+	// The ID of the blob.
+	id       string
+	trigrams []trigram
+}
+
+// SearchIndex is an in-memory trigram index of text files in the tree of a
+// commit, which narrows down files to be searched by regular expressions. It is
+// safe for concurrent use, and Git commands are never run while holding the
+// lock.
+type SearchIndex struct {
+	repo *Repository
+	opt  SearchIndexOptions
+
+	lock     sync.RWMutex
+	commitID string
+	files    map[string]*indexedFile
+	postings map[trigram]map[string]struct{}
+}
+
+// blobToIndex is a blob in the tree to be indexed.
+type blobToIndex struct {
+	name string
+	id   string
+}
+
+// isIndexableMode returns true if the mode is a regular or executable file.
+func isIndexableMode(mode string) bool {
+	return mode == "100644" || mode == "100755" || mode == "100664"
+}
+
+// BuildSearchIndex builds a search index of text files in the tree of given
+// revision of the repository. Binary files, symbolic links and submodules are
+// skipped.
+func (r *Repository) BuildSearchIndex(rev string, opts ...SearchIndexOptions) (*SearchIndex, error) {
+	var opt SearchIndexOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.MaxFileSize <= 0 {
+		opt.MaxFileSize = 1 << 20
+	}
+
+	commit, err := r.CatFileCommit(rev, CatFileCommitOptions{CommandOptions: opt.CommandOptions})
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &SearchIndex{
+		repo:     r,
+		opt:      opt,
+		commitID: commit.ID.String(),
+		files:    make(map[string]*indexedFile),
+		postings: make(map[trigram]map[string]struct{}),
+	}
+
+	// List all files along with their sizes at once to skip large files before
+	// reading any content.
+	stdout, err := NewCommand("ls-tree", "-r", "-l", "-z").
+		AddOptions(opt.CommandOptions).
+		AddArgs(idx.commitID).
+		RunInDir(r.path)
+	if err != nil {
+		return nil, err
+	}
+	blobs, err := parseLsTreeSizes(stdout, opt.MaxFileSize)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := idx.loadFiles(blobs)
+	if err != nil {
+		return nil, err
+	}
+	for name, file := range files {
+		idx.addFile(name, file)
+	}
+	return idx, nil
+}
+
+// parseLsTreeSizes parses the output of "git ls-tree -r -l -z", and returns
+// blobs of regular or executable files that are not larger than maxSize, e.g.
+//
+//	100644 blob 3b18e512dba79e4c8300dd08aeb37f8e728b8dad      12\tREADME.txt\x00
+func parseLsTreeSizes(data []byte, maxSize int64) ([]blobToIndex, error) {
+	var blobs []blobToIndex
+	for _, entry := range bytes.Split(data, []byte{0}) {
+		if len(entry) == 0 {
+			continue
+		}
+
+		tab := bytes.IndexByte(entry, '\t')
+		if tab < 0 {
+			return nil, fmt.Errorf("malformed tree entry: %q", entry)
+		}
+		fields := strings.Fields(string(entry[:tab]))
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("malformed tree entry: %q", entry)
+		}
+		if !isIndexableMode(fields[0]) {
+			continue
+		}
+
+		size, err := strconv.ParseInt(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse size of %q: %v", entry[tab+1:], err)
+		}
+		if size > maxSize {
+			continue
+		}
+		blobs = append(blobs, blobToIndex{name: string(entry[tab+1:]), id: fields[2]})
+	}
+	return blobs, nil
+}
+
+// loadFiles reads contents of blobs and returns the text files to be added to
+// the index by their paths. Blobs larger than the maximum file size are
+// skipped.
+func (idx *SearchIndex) loadFiles(blobs []blobToIndex) (map[string]*indexedFile, error) {
+	ids := make([]string, len(blobs))
+	for i := range blobs {
+		ids[i] = blobs[i].id
+	}
+
+	files := make(map[string]*indexedFile, len(blobs))
+	err := idx.repo.catFileBatch(ids, idx.opt.MaxFileSize, idx.opt.CommandOptions, func(i int, content []byte) error {
+		if content == nil || isBinary(content) {
+			return nil
+		}
+		files[blobs[i].name] = &indexedFile{
+			id:       blobs[i].id,
+			trigrams: trigramsOf(bytes.ToLower(content)),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// catFileBatch reads contents of blobs by given IDs with a single "git
+// cat-file --batch", and calls fn with the index of each ID and its content in
+// the same order. The content is nil when the blob is larger than maxSize,
+// which has no limit when not positive. It stops and returns the error when fn
+// returns one.
+func (r *Repository) catFileBatch(ids []string, maxSize int64, opt CommandOptions, fn func(i int, content []byte) error) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	stdin := new(bytes.Buffer)
+	for _, id := range ids {
+		stdin.WriteString(id)
+		stdin.WriteByte('\n')
+	}
+
+	stdout, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		err := parseCatFileBatch(bufio.NewReader(stdout), len(ids), maxSize, fn)
+		// Stop the command when parsing stops, otherwise drain the rest of output
+		// to not block the command.
+		if err != nil {
+			_ = stdout.CloseWithError(err)
+		} else {
+			_, _ = io.Copy(io.Discard, stdout)
+		}
+		done <- err
+	}()
+
+	stderr := new(bytes.Buffer)
+	err := NewCommand("cat-file", "--batch").
+		AddOptions(opt).
+		RunInDirWithOptions(r.path, RunInDirOptions{
+			Stdin:  stdin,
+			Stdout: w,
+			Stderr: stderr,
+		})
+	_ = w.Close() // Close writer to exit parsing goroutine
+	parseErr := <-done
+	if parseErr != nil {
+		return parseErr
+	}
+	if err != nil {
+		return concatenateError(err, stderr.String())
+	}
+	return nil
+}
+
+// parseCatFileBatch parses n objects from the output of "git cat-file
+// --batch", each of which has a header followed by the content, e.g.
+//
+//	3b18e512dba79e4c8300dd08aeb37f8e728b8dad blob 12
+//	hello world
+func parseCatFileBatch(r *bufio.Reader, n int, maxSize int64, fn func(i int, content []byte) error) error {
+	for i := 0; i < n; i++ {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("read header: %v", err)
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			return fmt.Errorf("malformed object header: %q", header)
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("parse size of %q: %v", fields[0], err)
+		}
+
+		// The content is always followed by a newline
+		if maxSize > 0 && size > maxSize {
+			if _, err = io.CopyN(io.Discard, r, size+1); err != nil {
+				return fmt.Errorf("skip %q: %v", fields[0], err)
+			}
+			if err = fn(i, nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		content := make([]byte, size+1)
+		if _, err = io.ReadFull(r, content); err != nil {
+			return fmt.Errorf("read %q: %v", fields[0], err)
+		}
+		if err = fn(i, content[:size]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addFile adds the file to the index.
+func (idx *SearchIndex) addFile(name string, file *indexedFile) {
+	idx.files[name] = file
+	for _, t := range file.trigrams {
+		paths, ok := idx.postings[t]
+		if !ok {
+			paths = make(map[string]struct{})
+			idx.postings[t] = paths
+		}
+		paths[name] = struct{}{}
+	}
+}
+
+// removeFile removes the file from the index.
+func (idx *SearchIndex) removeFile(name string) {
+	file, ok := idx.files[name]
+	if !ok {
+		return
+	}
+
+	for _, t := range file.trigrams {
+		paths := idx.postings[t]
+		delete(paths, name)
+		if len(paths) == 0 {
+			delete(idx.postings, t)
+		}
+	}
+	delete(idx.files, name)
+}
+
+// isBinary returns true if the content looks binary, i.e. has a NUL byte in
+// the first 8000 bytes like Git does.
+func isBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) > -1
+}
+
+// CommitID returns the ID of the commit that the index is built for.
+func (idx *SearchIndex) CommitID() string {
+	idx.lock.RLock()
+	defer idx.lock.RUnlock()
+	return idx.commitID
+}
+
+// parseDiffTreeBlobs parses the output of "git diff-tree -r -z --no-renames",
+// and returns paths of all changed files along with the blobs to be indexed,
+// e.g.
+//
+//	:100644 100644 <old blob> <new blob> M\x00README.txt\x00
+func parseDiffTreeBlobs(data []byte) (names []string, blobs []blobToIndex, _ error) {
+	fields := bytes.Split(data, []byte{0})
+	for i := 0; i+1 < len(fields); i += 2 {
+		header := strings.Fields(string(bytes.TrimPrefix(fields[i], []byte(":"))))
+		if len(header) != 5 {
+			return nil, nil, fmt.Errorf("malformed diff entry: %q", fields[i])
+		}
+
+		name := string(fields[i+1])
+		names = append(names, name)
+		if header[4] != "D" && isIndexableMode(header[1]) {
+			blobs = append(blobs, blobToIndex{name: name, id: header[3]})
+		}
+	}
+	return names, blobs, nil
+}
+
+// Update updates the index to the tree of given revision incrementally, by only
+// reindexing files that changed since the indexed commit.
+func (idx *SearchIndex) Update(rev string) error {
+	commit, err := idx.repo.CatFileCommit(rev, CatFileCommitOptions{CommandOptions: idx.opt.CommandOptions})
+	if err != nil {
+		return err
+	}
+	commitID := commit.ID.String()
+
+	for {
+		idx.lock.RLock()
+		base := idx.commitID
+		idx.lock.RUnlock()
+		if base == commitID {
+			return nil
+		}
+
+		// Renames must be listed as deletions and additions to remove old paths
+		stdout, err := NewCommand("diff-tree", "-r", "-z", "--no-renames").
+			AddOptions(idx.opt.CommandOptions).
+			AddArgs(base, commitID).
+			RunInDir(idx.repo.path)
+		if err != nil {
+			return err
+		}
+		names, blobs, err := parseDiffTreeBlobs(stdout)
+		if err != nil {
+			return err
+		}
+		files, err := idx.loadFiles(blobs)
+		if err != nil {
+			return err
+		}
+
+		idx.lock.Lock()
+		// Start over from the new state when the index has been updated meanwhile
+		if idx.commitID != base {
+			idx.lock.Unlock()
+			continue
+		}
+		for _, name := range names {
+			idx.removeFile(name)
+		}
+		for name, file := range files {
+			idx.addFile(name, file)
+		}
+		idx.commitID = commitID
+		idx.lock.Unlock()
+		return nil
+	}
+}
+
+// candidates returns sorted paths of files that may match the query.
+func (idx *SearchIndex) candidates(q *trigramQuery) []string {
+	set := idx.evaluate(q)
+	paths := make([]string, 0, len(set))
+	if set == nil {
+		for name := range idx.files {
+			paths = append(paths, name)
+		}
+	} else {
+		for name := range set {
+			paths = append(paths, name)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// evaluate returns the set of paths of files that match the query, or nil for
+// all files.
+func (idx *SearchIndex) evaluate(q *trigramQuery) map[string]struct{} {
+	switch q.op {
+	case trigramAnd:
+		var set map[string]struct{}
+		intersect := func(other map[string]struct{}) {
+			if set == nil {
+				set = make(map[string]struct{}, len(other))
+				for name := range other {
+					set[name] = struct{}{}
+				}
+				return
+			}
+			for name := range set {
+				if _, ok := other[name]; !ok {
+					delete(set, name)
+				}
+			}
+		}
+		for _, t := range q.trigrams {
+			intersect(idx.postings[t])
+		}
+		for _, sub := range q.subs {
+			if other := idx.evaluate(sub); other != nil {
+				intersect(other)
+			}
+		}
+		return set
+
+	case trigramOr:
+		set := make(map[string]struct{})
+		for _, t := range q.trigrams {
+			for name := range idx.postings[t] {
+				set[name] = struct{}{}
+			}
+		}
+		for _, sub := range q.subs {
+			other := idx.evaluate(sub)
+			if other == nil {
+				return nil
+			}
+			for name := range other {
+				set[name] = struct{}{}
+			}
+		}
+		return set
+	}
+	return nil
+}
+
+// errSearchIndexStop is used to stop reading contents of candidates.
+var errSearchIndexStop = errors.New("stop")
+
+// SearchIndexQueryOptions contains optional arguments for searching with a
+// search index.
+type SearchIndexQueryOptions struct {
+	// Whether to do case insensitive search.
+	IgnoreCase bool
+	// The maximum number of results in total.
+	MaxResults int
+}
+
+// Search returns the results of matching the regular expression in Go syntax
+// against lines of indexed files, in the order of paths and line numbers. The
+// tree of results is the ID of the indexed commit.
+func (idx *SearchIndex) Search(pattern string, opts ...SearchIndexQueryOptions) ([]*GrepResult, error) {
+	var opt SearchIndexQueryOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	if opt.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+	q := analyzeRegexp(parsed.Simplify()).toQuery()
+
+	// Take a snapshot of candidates to read contents without holding the lock
+	idx.lock.RLock()
+	commitID := idx.commitID
+	names := idx.candidates(q)
+	ids := make([]string, len(names))
+	for i, name := range names {
+		ids[i] = idx.files[name].id
+	}
+	idx.lock.RUnlock()
+
+	var results []*GrepResult
+	err = idx.repo.catFileBatch(ids, 0, idx.opt.CommandOptions, func(i int, content []byte) error {
+		for lineNum, line := range bytes.Split(content, []byte("\n")) {
+			loc := re.FindIndex(line)
+			if loc == nil {
+				continue
+			}
+
+			line = bytes.TrimSuffix(line, []byte("\r"))
+			results = append(results, &GrepResult{
+				Tree:   commitID,
+				Path:   names[i],
+				Line:   lineNum + 1,
+				Column: loc[0] + 1,
+				Text:   string(line),
+			})
+			if opt.MaxResults > 0 && len(results) >= opt.MaxResults {
+				return errSearchIndexStop
+			}
+		}
+		return nil
+	})
+	if err != nil && err != errSearchIndexStop {
+		return nil, err
+	}
+	return results, nil
+}
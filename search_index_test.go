@@ -0,0 +1,151 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp/syntax"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchIndex_candidates(t *testing.T) {
+	idx := &SearchIndex{
+		files:    make(map[string]*indexedFile),
+		postings: make(map[trigram]map[string]struct{}),
+	}
+	for name, content := range map[string]string{
+		"a.txt": "hello world",
+		"b.txt": "HELLO there",
+		"c.txt": "goodbye world",
+		"d.txt": "hi",
+	} {
+		file := &indexedFile{trigrams: trigramsOf(bytes.ToLower([]byte(content)))}
+		idx.files[name] = file
+		for _, t := range file.trigrams {
+			if idx.postings[t] == nil {
+				idx.postings[t] = make(map[string]struct{})
+			}
+			idx.postings[t][name] = struct{}{}
+		}
+	}
+
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{pattern: "hello", want: []string{"a.txt", "b.txt"}},
+		{pattern: "(?i)Hello", want: []string{"a.txt", "b.txt"}},
+		{pattern: "hello world", want: []string{"a.txt"}},
+		{pattern: "hello|goodbye", want: []string{"a.txt", "b.txt", "c.txt"}},
+		{pattern: "hello.*world", want: []string{"a.txt"}},
+		{pattern: "wor[lk]d", want: []string{"a.txt", "c.txt"}},
+		{pattern: "(there|world)$", want: []string{"a.txt", "b.txt", "c.txt"}},
+		{pattern: "nothing", want: []string{}},
+
+		// Patterns that can not be narrowed down
+		{pattern: "h.", want: []string{"a.txt", "b.txt", "c.txt", "d.txt"}},
+		{pattern: "x*", want: []string{"a.txt", "b.txt", "c.txt", "d.txt"}},
+		{pattern: "hello|.", want: []string{"a.txt", "b.txt", "c.txt", "d.txt"}},
+	}
+	for _, test := range tests {
+		t.Run(test.pattern, func(t *testing.T) {
+			re, err := syntax.Parse(test.pattern, syntax.Perl)
+			require.NoError(t, err)
+			got := idx.candidates(analyzeRegexp(re.Simplify()).toQuery())
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestRepository_BuildSearchIndex(t *testing.T) {
+	path := tempPath()
+	defer func() { _ = os.RemoveAll(path) }()
+	require.NoError(t, Init(path))
+	r, err := Open(path)
+	require.NoError(t, err)
+
+	alice := &Signature{Name: "alice", Email: "alice@example.com"}
+	commit := func(files map[string]string, message string) string {
+		t.Helper()
+		for name, content := range files {
+			path := filepath.Join(r.Path(), name)
+			if content == "" {
+				require.NoError(t, os.Remove(path))
+				continue
+			}
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		}
+		require.NoError(t, r.Add(AddOptions{All: true}))
+		require.NoError(t, r.Commit(alice, message))
+		id, err := r.RevParse("HEAD")
+		require.NoError(t, err)
+		return id
+	}
+
+	first := commit(map[string]string{
+		"search/a.go":     "package search\n\nfunc Hello() {}\n",
+		"search/b/c.txt":  "say hello to the world\n",
+		"search/bin.dat":  "\x00hello\n",
+		"search/large.md": strings.Repeat("hello ", 20),
+	}, "Add files to search")
+
+	idx, err := r.BuildSearchIndex(first, SearchIndexOptions{MaxFileSize: 64})
+	require.NoError(t, err)
+	assert.Equal(t, first, idx.CommitID())
+
+	results, err := idx.Search(`func [A-Z]\w+`)
+	require.NoError(t, err)
+	assert.Equal(t, []*GrepResult{
+		{Tree: first, Path: "search/a.go", Line: 3, Column: 1, Text: "func Hello() {}"},
+	}, results)
+
+	results, err = idx.Search("hello", SearchIndexQueryOptions{IgnoreCase: true})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "search/a.go", results[0].Path)
+	assert.Equal(t, 6, results[0].Column)
+	assert.Equal(t, "search/b/c.txt", results[1].Path)
+	assert.Equal(t, 5, results[1].Column)
+
+	results, err = idx.Search("hello", SearchIndexQueryOptions{IgnoreCase: true, MaxResults: 1})
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	_, err = idx.Search("(")
+	assert.Error(t, err)
+
+	second := commit(map[string]string{
+		"search/a.go":    "package search\n\nfunc Goodbye() {}\n",
+		"search/b/c.txt": "",
+		"search/d.txt":   "say hello to the world\n",
+		"search/e.md":    strings.Repeat("hello ", 20),
+	}, "Update files to search")
+	require.NoError(t, idx.Update(second))
+	assert.Equal(t, second, idx.CommitID())
+
+	results, err = idx.Search("hello", SearchIndexQueryOptions{IgnoreCase: true})
+	require.NoError(t, err)
+	assert.Equal(t, []*GrepResult{
+		{Tree: second, Path: "search/d.txt", Line: 1, Column: 5, Text: "say hello to the world"},
+	}, results)
+
+	// The results are the same as the ones of grep
+	grepped, err := r.Grep("[A-Z][a-z]+", GrepOptions{SkipBinary: true, ExtendedRegexp: true})
+	require.NoError(t, err)
+	results, err = idx.Search("[A-Z][a-z]+")
+	require.NoError(t, err)
+	require.Len(t, results, len(grepped))
+	for i := range grepped {
+		grepped[i].Tree = second
+	}
+	assert.Equal(t, grepped, results)
+}
@@ -5,8 +5,10 @@
 package git
 
 import (
+	"crypto/sha1"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
 )
@@ -14,6 +16,10 @@ import (
 // EmptyID is an ID with empty SHA-1 hash.
 const EmptyID = "0000000000000000000000000000000000000000"
 
+// EmptyTreeID is the ID of the empty tree object, which could be used as the
+// base revision to compare with a root commit.
+const EmptyTreeID = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
 // SHA1 is the SHA-1 hash of a Git object.
 type SHA1 struct {
 	bytes [20]byte
@@ -87,3 +93,12 @@ func NewIDFromString(s string) (*SHA1, error) {
 	}
 	return NewID(b)
 }
+
+// hashObject returns the ID of the object with given type and raw data, as
+// computed by "git hash-object".
+func hashObject(typ ObjectType, data []byte) *SHA1 {
+	h := sha1.New()
+	_, _ = fmt.Fprintf(h, "%s %d\x00", typ, len(data))
+	_, _ = h.Write(data)
+	return MustID(h.Sum(nil))
+}
@@ -6,6 +6,7 @@ package git
 
 import (
 	"bytes"
+	"fmt"
 	"strconv"
 	"time"
 )
@@ -30,22 +31,41 @@ type Signature struct {
 // This method should only be used for parsing author and committer.
 func parseSignature(line []byte) (*Signature, error) {
 	emailStart := bytes.IndexByte(line, '<')
-	emailEnd := bytes.IndexByte(line, '>')
+	emailEnd := bytes.IndexByte(line[emailStart+1:], '>')
+	if emailStart < 0 || emailEnd < 0 {
+		return nil, fmt.Errorf("malformed signature: %q", line)
+	}
+	emailEnd += emailStart + 1
 	sig := &Signature{
-		Name:  string(line[:emailStart-1]),
+		Name:  string(bytes.TrimSpace(line[:emailStart])),
 		Email: string(line[emailStart+1 : emailEnd]),
 	}
 
+	// The time is absent
+	if emailEnd+2 >= len(line) {
+		return sig, nil
+	}
+
 	// Check the date format
 	firstChar := line[emailEnd+2]
 	if firstChar >= 48 && firstChar <= 57 { // ASCII code for 0-9
 		timestop := bytes.IndexByte(line[emailEnd+2:], ' ')
+		if timestop < 0 {
+			timestop = len(line) - emailEnd - 2
+		}
 		timestamp := line[emailEnd+2 : emailEnd+2+timestop]
 		seconds, err := strconv.ParseInt(string(timestamp), 10, 64)
 		if err != nil {
 			return nil, err
 		}
 		sig.When = time.Unix(seconds, 0)
+
+		// Preserve the original timezone offset, e.g. "+0200"
+		if tzstart := emailEnd + 2 + timestop + 1; tzstart < len(line) {
+			if loc, ok := parseTimezone(line[tzstart:]); ok {
+				sig.When = sig.When.In(loc)
+			}
+		}
 		return sig, nil
 	}
 
@@ -56,3 +76,68 @@ func parseSignature(line []byte) (*Signature, error) {
 	}
 	return sig, nil
 }
+
+// parseTimezone parses the timezone offset in the form of "+0200" to a fixed
+// zone location.
+func parseTimezone(tz []byte) (*time.Location, bool) {
+	if len(tz) != 5 || (tz[0] != '+' && tz[0] != '-') {
+		return nil, false
+	}
+	hhmm, err := strconv.Atoi(string(tz[1:]))
+	if err != nil {
+		return nil, false
+	}
+
+	offset := (hhmm/100)*3600 + (hhmm%100)*60
+	if tz[0] == '-' {
+		offset = -offset
+	}
+	return time.FixedZone("", offset), true
+}
+
+// timestamp returns the time of the signature in the form of Git object
+// headers, e.g. "1378823654 +0200".
+func (s *Signature) timestamp() string {
+	return strconv.FormatInt(s.When.Unix(), 10) + " " + s.When.Format("-0700")
+}
+
+// String returns the signature in the canonical form of Git object headers,
+// e.g. "Patrick Gundlach <gundlach@speedata.de> 1378823654 +0200". The time is
+// omitted when it is zero.
+func (s *Signature) String() string {
+	if s.When.IsZero() {
+		return s.Name + " <" + s.Email + ">"
+	}
+	return s.Name + " <" + s.Email + "> " + s.timestamp()
+}
+
+// MarshalText implements encoding.TextMarshaler. It returns the same as
+// String.
+func (s *Signature) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. It parses the signature in
+// the form returned by String.
+func (s *Signature) UnmarshalText(text []byte) error {
+	sig, err := parseSignature(text)
+	if err != nil {
+		return err
+	}
+	*s = *sig
+	return nil
+}
+
+// signatureEnvs returns the environment variables to set given signature as
+// the author or committer, as specified by the role (i.e. "AUTHOR" or
+// "COMMITTER"). The time is set only when it is not zero.
+func signatureEnvs(role string, sig *Signature) []string {
+	envs := []string{
+		"GIT_" + role + "_NAME=" + sig.Name,
+		"GIT_" + role + "_EMAIL=" + sig.Email,
+	}
+	if !sig.When.IsZero() {
+		envs = append(envs, "GIT_"+role+"_DATE="+sig.timestamp())
+	}
+	return envs
+}
@@ -5,10 +5,12 @@
 package git
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_parseSignature(t *testing.T) {
@@ -42,6 +44,90 @@ func Test_parseSignature(t *testing.T) {
 			assert.Equal(t, test.expSig.Name, sig.Name)
 			assert.Equal(t, test.expSig.Email, sig.Email)
 			assert.Equal(t, test.expSig.When.Unix(), sig.When.Unix())
+			_, offset := sig.When.Zone()
+			assert.Equal(t, 2*3600, offset)
 		})
 	}
+
+	t.Run("no time", func(t *testing.T) {
+		sig, err := parseSignature([]byte("Patrick Gundlach <gundlach@speedata.de>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, &Signature{Name: "Patrick Gundlach", Email: "gundlach@speedata.de"}, sig)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		_, err := parseSignature([]byte("Patrick Gundlach gundlach@speedata.de"))
+		assert.Error(t, err)
+	})
+}
+
+func TestSignature_String(t *testing.T) {
+	tests := []struct {
+		sig  *Signature
+		want string
+	}{
+		{
+			sig: &Signature{
+				Name:  "Patrick Gundlach",
+				Email: "gundlach@speedata.de",
+				When:  time.Unix(1378823654, 0).In(time.FixedZone("", 2*3600)),
+			},
+			want: "Patrick Gundlach <gundlach@speedata.de> 1378823654 +0200",
+		},
+		{
+			sig: &Signature{
+				Name:  "alice",
+				Email: "alice@example.com",
+				When:  time.Unix(1581250680, 0).In(time.FixedZone("", -(9*3600 + 30*60))),
+			},
+			want: "alice <alice@example.com> 1581250680 -0930",
+		},
+		{
+			sig: &Signature{
+				Name:  "alice",
+				Email: "alice@example.com",
+				When:  time.Unix(1581250680, 0).UTC(),
+			},
+			want: "alice <alice@example.com> 1581250680 +0000",
+		},
+		{
+			sig: &Signature{
+				Name:  "alice",
+				Email: "alice@example.com",
+			},
+			want: "alice <alice@example.com>",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			assert.Equal(t, test.want, test.sig.String())
+
+			// Round-trip through the text form
+			text, err := test.sig.MarshalText()
+			require.NoError(t, err)
+			var got Signature
+			require.NoError(t, got.UnmarshalText(text))
+			assert.Equal(t, test.sig.Name, got.Name)
+			assert.Equal(t, test.sig.Email, got.Email)
+			assert.True(t, test.sig.When.Equal(got.When))
+			assert.Equal(t, test.want, got.String())
+		})
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		sig := &Signature{
+			Name:  "alice",
+			Email: "alice@example.com",
+			When:  time.Unix(1581250680, 0).In(time.FixedZone("", 8*3600)),
+		}
+		data, err := json.Marshal(map[string]*Signature{"author": sig})
+		require.NoError(t, err)
+		assert.Equal(t, `{"author":"alice \u003calice@example.com\u003e 1581250680 +0800"}`, string(data))
+
+		var got map[string]*Signature
+		require.NoError(t, json.Unmarshal(data, &got))
+		assert.Equal(t, sig.String(), got["author"].String())
+	})
 }
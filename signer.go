@@ -0,0 +1,203 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Signer signs the payload of commit and tag objects. It allows signing with
+// keys that are not accessible by Git, e.g. keys held by an external service.
+type Signer interface {
+	// Format returns the format of signatures produced by the signer.
+	Format() SignatureFormat
+	// Sign returns the armored signature of given payload.
+	Sign(payload []byte) ([]byte, error)
+}
+
+// gitSigner is a Signer that Git is able to sign with natively, i.e. through
+// the "-S" flag of "git commit" and "git commit-tree", or the "-s" flag of
+// "git tag".
+type gitSigner interface {
+	Signer
+	// signingArgs returns the global arguments and environment variables for Git
+	// to sign with the signer.
+	signingArgs() (args, envs []string)
+}
+
+// GPGSigner signs with a GnuPG key.
+type GPGSigner struct {
+	// The ID of the key to sign with, which can be anything that GnuPG accepts
+	// as a user ID, e.g. the key ID or the email of the key.
+	KeyID string
+	// The GnuPG home directory that contains the keyring. The default of GnuPG
+	// is used when not set.
+	Home string
+}
+
+var _ gitSigner = (*GPGSigner)(nil)
+
+// Format returns the format of signatures produced by the signer.
+func (*GPGSigner) Format() SignatureFormat {
+	return SignatureFormatOpenPGP
+}
+
+func (s *GPGSigner) envs() []string {
+	if s.Home == "" {
+		return nil
+	}
+	return []string{"GNUPGHOME=" + s.Home}
+}
+
+// Sign returns the armored signature of given payload.
+func (s *GPGSigner) Sign(payload []byte) ([]byte, error) {
+	return runSigningProgram(s.envs(), payload, "gpg", "--status-fd=2", "-bsau", s.KeyID)
+}
+
+func (s *GPGSigner) signingArgs() (args, envs []string) {
+	return []string{
+		"-c", "gpg.format=" + string(SignatureFormatOpenPGP),
+		"-c", "user.signingKey=" + s.KeyID,
+	}, s.envs()
+}
+
+// SSHSigner signs with an SSH key.
+type SSHSigner struct {
+	// The path of the private key file to sign with.
+	KeyFile string
+}
+
+var _ gitSigner = (*SSHSigner)(nil)
+
+// Format returns the format of signatures produced by the signer.
+func (*SSHSigner) Format() SignatureFormat {
+	return SignatureFormatSSH
+}
+
+// Sign returns the armored signature of given payload.
+func (s *SSHSigner) Sign(payload []byte) ([]byte, error) {
+	return runSigningProgram(nil, payload, "ssh-keygen", "-Y", "sign", "-n", "git", "-f", s.KeyFile)
+}
+
+func (s *SSHSigner) signingArgs() (args, envs []string) {
+	return []string{
+		"-c", "gpg.format=" + string(SignatureFormatSSH),
+		"-c", "user.signingKey=" + s.KeyFile,
+	}, nil
+}
+
+// runSigningProgram runs given signing program with the payload as the stdin,
+// and returns its stdout as the signature.
+func runSigningProgram(envs []string, payload []byte, name string, args ...string) ([]byte, error) {
+	cmd := exec.Command(name, args...)
+	if len(envs) > 0 {
+		cmd.Env = append(os.Environ(), envs...)
+	}
+	cmd.Stdin = bytes.NewReader(payload)
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return nil, concatenateError(err, stderr.String())
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("%s produced no signature", name)
+	}
+	return stdout.Bytes(), nil
+}
+
+// signingCommand returns a new command with given arguments, which signs with
+// given signer if it is supported natively by Git. The returned bool indicates
+// whether the command signs.
+func signingCommand(signer Signer, args ...string) (*Command, bool) {
+	s, ok := signer.(gitSigner)
+	if !ok {
+		return NewCommand(args...), false
+	}
+	signingArgs, envs := s.signingArgs()
+	return NewCommand(append(signingArgs, args...)...).AddEnvs(envs...), true
+}
+
+// signObject signs the commit or tag object with given ID using the signer,
+// writes the signed object to the object database and returns its ID. The
+// original object is left as-is.
+func signObject(repoPath, typ, id string, signer Signer, timeout time.Duration) (*SHA1, error) {
+	data, err := NewCommand("cat-file", typ, id).RunInDirWithTimeout(timeout, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := signer.Sign(data)
+	if err != nil {
+		return nil, fmt.Errorf("sign: %v", err)
+	}
+	signed, err := appendObjectSignature(typ, data, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	err = NewCommand("hash-object", "-t", typ, "-w", "--stdin").
+		WithTimeout(timeout).
+		RunInDirWithOptions(repoPath, RunInDirOptions{
+			Stdin:  bytes.NewReader(signed),
+			Stdout: stdout,
+			Stderr: stderr,
+		})
+	if err != nil {
+		return nil, concatenateError(err, stderr.String())
+	}
+	return NewIDFromString(strings.TrimSpace(stdout.String()))
+}
+
+// appendObjectSignature adds the signature to the raw data of the commit or tag
+// object. The signature of a commit is added as the "gpgsig" header, and the
+// signature of a tag is appended to the message.
+func appendObjectSignature(typ string, data, signature []byte) ([]byte, error) {
+	signature = bytes.TrimRight(signature, "\n")
+	switch typ {
+	case "commit":
+		end := bytes.Index(data, []byte("\n\n"))
+		if end < 0 {
+			end = len(data)
+			if end > 0 && data[end-1] == '\n' {
+				end--
+			}
+		}
+		if bytes.HasPrefix(data, []byte("gpgsig ")) || bytes.Contains(data[:end], []byte("\ngpgsig ")) {
+			return nil, errors.New("the commit is already signed")
+		}
+
+		buf := bytes.NewBuffer(make([]byte, 0, len(data)+len(signature)+16))
+		buf.Write(data[:end])
+		buf.WriteString("\ngpgsig ")
+		buf.Write(bytes.ReplaceAll(signature, []byte("\n"), []byte("\n ")))
+		buf.Write(data[end:])
+		return buf.Bytes(), nil
+
+	case "tag":
+		if trailingSignatureStart(data) >= 0 {
+			return nil, errors.New("the tag is already signed")
+		}
+
+		buf := bytes.NewBuffer(make([]byte, 0, len(data)+len(signature)+1))
+		buf.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		buf.Write(signature)
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unsupported object type %q", typ)
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 The Gogs Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package git
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// externalSigner is a Signer that is not supported by Git natively.
+type externalSigner struct {
+	Signer
+}
+
+func Test_appendObjectSignature(t *testing.T) {
+	signature := []byte("-----BEGIN SSH SIGNATURE-----\nU1NIU0lH\n-----END SSH SIGNATURE-----\n")
+
+	t.Run("commit", func(t *testing.T) {
+		data := []byte(`tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+author alice <alice@example.com> 1581250680 +0800
+committer alice <alice@example.com> 1581250680 +0800
+
+Initial commit
+`)
+		got, err := appendObjectSignature("commit", data, signature)
+		require.NoError(t, err)
+		assert.Equal(t, `tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+author alice <alice@example.com> 1581250680 +0800
+committer alice <alice@example.com> 1581250680 +0800
+gpgsig -----BEGIN SSH SIGNATURE-----
+ U1NIU0lH
+ -----END SSH SIGNATURE-----
+
+Initial commit
+`, string(got))
+
+		commit, err := parseCommit(got)
+		require.NoError(t, err)
+		require.NotNil(t, commit.Signature())
+		assert.Equal(t, string(signature), commit.Signature().Signature)
+		assert.Equal(t, string(data), commit.Signature().Payload)
+
+		_, err = appendObjectSignature("commit", got, signature)
+		assert.Error(t, err)
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		data := []byte(`object 4b825dc642cb6eb9a060e54bf8d69288fbee4904
+type tree
+tag v1.0.0
+tagger alice <alice@example.com> 1581250680 +0800
+
+Release v1.0.0
+`)
+		got, err := appendObjectSignature("tag", data, signature)
+		require.NoError(t, err)
+		assert.Equal(t, string(data)+string(signature), string(got))
+
+		tag, err := parseTag(got)
+		require.NoError(t, err)
+		require.NotNil(t, tag.Signature())
+		assert.Equal(t, string(signature), tag.Signature().Signature)
+		assert.Equal(t, string(data), tag.Signature().Payload)
+
+		_, err = appendObjectSignature("tag", got, signature)
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := appendObjectSignature("blob", []byte("hello"), signature)
+		assert.Error(t, err)
+	})
+}
+
+func Test_signingCommand(t *testing.T) {
+	cmd, signing := signingCommand(nil, "commit")
+	assert.False(t, signing)
+	assert.Equal(t, "git commit", cmd.String())
+
+	cmd, signing = signingCommand(&SSHSigner{KeyFile: "/tmp/id_ed25519"}, "commit")
+	assert.True(t, signing)
+	assert.Equal(t, "git -c gpg.format=ssh -c user.signingKey=/tmp/id_ed25519 commit", cmd.String())
+
+	cmd, signing = signingCommand(&GPGSigner{KeyID: "ABCD", Home: "/tmp/gnupg"}, "tag")
+	assert.True(t, signing)
+	assert.Equal(t, "git -c gpg.format=openpgp -c user.signingKey=ABCD tag", cmd.String())
+	assert.Equal(t, []string{"GNUPGHOME=/tmp/gnupg"}, cmd.envs)
+
+	cmd, signing = signingCommand(externalSigner{&SSHSigner{}}, "commit")
+	assert.False(t, signing)
+	assert.Equal(t, "git commit", cmd.String())
+}
@@ -6,12 +6,13 @@ package git
 
 // Tag contains information of a Git tag.
 type Tag struct {
-	typ      ObjectType
-	id       *SHA1
-	commitID *SHA1 // The ID of the underlying commit
-	refspec  string
-	tagger   *Signature
-	message  string
+	typ       ObjectType
+	id        *SHA1
+	commitID  *SHA1 // The ID of the underlying commit
+	refspec   string
+	tagger    *Signature
+	message   string
+	signature *ObjectSignature
 
 	repo *Repository
 }
@@ -46,6 +47,12 @@ func (t *Tag) Message() string {
 	return t.message
 }
 
+// Signature returns the cryptographic signature of the tag. It returns nil if
+// the tag is not signed.
+func (t *Tag) Signature() *ObjectSignature {
+	return t.signature
+}
+
 // Commit returns the underlying commit of the tag.
 func (t *Tag) Commit(opts ...CatFileCommitOptions) (*Commit, error) {
 	return t.repo.CatFileCommit(t.commitID.String(), opts...)
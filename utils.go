@@ -7,8 +7,12 @@ package git
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 	"sync"
+
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
 )
 
 // objectCache provides thread-safe cache operations. TODO(@unknwon): Use
@@ -82,3 +86,75 @@ func bytesToStrings(in []byte) []string {
 	}
 	return strings.Split(s, "\n")
 }
+
+// decodeToUTF8 transcodes the string from given encoding (e.g. "ISO-8859-1")
+// to UTF-8. It returns the string as-is if the encoding is unknown or the
+// string cannot be transcoded.
+func decodeToUTF8(encoding, s string) string {
+	enc, err := htmlindex.Get(encoding)
+	if err != nil || enc == unicode.UTF8 {
+		return s
+	}
+
+	decoded, err := enc.NewDecoder().String(s)
+	if err != nil {
+		return s
+	}
+	return decoded
+}
+
+// quotePath returns the path quoted in C-style by the same rules as Git with
+// "core.quotePath" enabled, i.e. when it contains double quotes, backslashes,
+// control characters or non-ASCII characters. Otherwise, it returns the path as
+// is.
+func quotePath(path string) string {
+	var b strings.Builder
+	quoted := false
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\a':
+			b.WriteString(`\a`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\v':
+			b.WriteString(`\v`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if c >= 0x20 && c < 0x7f {
+				b.WriteByte(c)
+				continue
+			}
+			_, _ = fmt.Fprintf(&b, `\%03o`, c)
+		}
+		quoted = true
+	}
+	if !quoted {
+		return path
+	}
+	return `"` + b.String() + `"`
+}
+
+// unquotePath returns the path as is unless it is quoted by Git for containing
+// special characters, e.g. "\"a\\tb.txt\"".
+func unquotePath(path string) string {
+	if len(path) < 2 || path[0] != '"' {
+		return path
+	}
+
+	unquoted, err := strconv.Unquote(path)
+	if err != nil {
+		return path
+	}
+	return unquoted
+}